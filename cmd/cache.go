@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// cacheDir returns the directory of the kw cache files
+func cacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "kw")
+}
+
+// writeCacheFile replaces the content of a cache file atomically,
+// other kw processes may be reading it at the same time
+func writeCacheFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leocomelli/kw/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// completionCacheTTL defines how long the values fetched from
+	// the cluster are reused by the shell completion
+	completionCacheTTL = 30 * time.Second
+	// completionTimeout avoids blocking the shell when a cluster is unreachable
+	completionTimeout = 5 * time.Second
+)
+
+// completeContextArg completes the context names and aliases, the
+// namespaces of the context are completed after the colon of ctx:ns
+func (o *ContextOptions) completeContextArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if err := o.load(); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	if i := strings.Index(toComplete, ":"); i >= 0 {
+		context := o.KubeWideConfig.ResolveAlias(toComplete[:i])
+		namespaces, err := completionNamespaces(o.PahtOptions, context)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		var values []string
+		for _, n := range filterPrefix(namespaces, toComplete[i+1:]) {
+			values = append(values, toComplete[:i+1]+n)
+		}
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names := make([]string, 0, len(o.Config.Contexts)+len(o.KubeWideConfig.Aliases))
+	for k := range o.Config.Contexts {
+		names = append(names, k)
+	}
+	for a := range o.KubeWideConfig.Aliases {
+		names = append(names, a)
+	}
+	sort.Strings(names)
+
+	return filterPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNamespaceArg completes the namespaces of the current context
+func (o *NamespaceOptions) completeNamespaceArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return completionResult(completionNamespaces(o.PahtOptions, ""))(toComplete)
+}
+
+// completeLogsFlags registers the completion of the namespace, pod and container flags
+func (o *LogOptions) completeLogsFlags(cmd *cobra.Command, po *clientcmd.PathOptions) {
+	_ = cmd.RegisterFlagCompletionFunc("namespace", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completionResult(completionNamespaces(po, ""))(toComplete)
+	})
+	_ = cmd.RegisterFlagCompletionFunc("pod", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if o.Namespace == "" {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completionResult(completionPods(po, o.Namespace))(toComplete)
+	})
+	_ = cmd.RegisterFlagCompletionFunc("container", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if o.Namespace == "" {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completionResult(completionContainers(po, o.Namespace, o.Pod))(toComplete)
+	})
+}
+
+// completionResult returns a function that filters the values by the
+// text being completed, the completion fails when err is not nil
+func completionResult(values []string, err error) func(string) ([]string, cobra.ShellCompDirective) {
+	return func(toComplete string) ([]string, cobra.ShellCompDirective) {
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return filterPrefix(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completionNamespaces returns the namespaces of a context, the
+// current context is used when it is empty
+func completionNamespaces(po *clientcmd.PathOptions, context string) ([]string, error) {
+	return cachedCompletion(po, context, []string{"namespaces"}, func(k *kubernetes.Kubernetes) ([]string, error) {
+		ns, err := k.Namespaces()
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(ns))
+		for _, n := range ns {
+			names = append(names, n.GetName())
+		}
+		return names, nil
+	})
+}
+
+// completionPods returns the pods of a namespace in the current context
+func completionPods(po *clientcmd.PathOptions, namespace string) ([]string, error) {
+	return cachedCompletion(po, "", []string{"pods", namespace}, func(k *kubernetes.Kubernetes) ([]string, error) {
+		pods, err := k.Pods(namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(pods))
+		for _, p := range pods {
+			names = append(names, p.GetName())
+		}
+		return names, nil
+	})
+}
+
+// completionContainers returns the containers of a pod in the current
+// context, or the containers of all pods when the pod is empty
+func completionContainers(po *clientcmd.PathOptions, namespace, pod string) ([]string, error) {
+	return cachedCompletion(po, "", []string{"containers", namespace, pod}, func(k *kubernetes.Kubernetes) ([]string, error) {
+		pods, err := k.ListPods(namespace, pod)
+		if err != nil {
+			return nil, err
+		}
+
+		seen := make(map[string]bool)
+		var names []string
+		for _, p := range pods {
+			for _, c := range p.Spec.Containers {
+				if !seen[c.Name] {
+					seen[c.Name] = true
+					names = append(names, c.Name)
+				}
+			}
+		}
+		return names, nil
+	})
+}
+
+// cachedCompletion returns the values fetched from the cluster of the
+// context, the values are cached on disk for completionCacheTTL because
+// the shell requests them again on every key press
+func cachedCompletion(po *clientcmd.PathOptions, context string, key []string, fetch func(*kubernetes.Kubernetes) ([]string, error)) ([]string, error) {
+	if context == "" {
+		c, err := po.GetStartingConfig()
+		if err != nil {
+			return nil, err
+		}
+		context = c.CurrentContext
+	}
+
+	path := completionCachePath(po, context, key)
+	if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) < completionCacheTTL {
+		if b, err := ioutil.ReadFile(path); err == nil {
+			return strings.Fields(string(b)), nil
+		}
+	}
+
+	k, err := kubernetes.NewKubernetesWithTimeout(po.LoadingRules, context, completionTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := fetch(k)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(values)
+
+	// the cache is an optimization, the values are returned anyway
+	_ = writeCacheFile(path, strings.Join(values, "\n"))
+
+	return values, nil
+}
+
+// completionCachePath returns the cache file of the values, the values
+// of contexts with the same name in other kubeconfig files are not shared
+func completionCachePath(po *clientcmd.PathOptions, context string, key []string) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", strings.Join(po.GetLoadingPrecedence(), string(filepath.ListSeparator)), context, strings.Join(key, "\x00"))
+	return filepath.Join(cacheDir(), "completion", fmt.Sprintf("%x", h.Sum64()))
+}
+
+// filterPrefix returns the values starting with the prefix
+func filterPrefix(values []string, prefix string) []string {
+	var filtered []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
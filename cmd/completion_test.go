@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestCompleteContextArg(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("XDG_CACHE_HOME", dir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+	os.Setenv("KW_CONFIG", filepath.Join(dir, "kw.yml"))
+	defer os.Unsetenv("KW_CONFIG")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "kw.yml"), []byte("aliases:\n  prod: gke_prod\n"), 0600))
+
+	kubeconfig := filepath.Join(dir, "config")
+	c := clientcmdapi.NewConfig()
+	c.Contexts["gke_prod"] = &clientcmdapi.Context{}
+	c.Contexts["minikube"] = &clientcmdapi.Context{}
+	assert.NoError(t, clientcmd.WriteToFile(*c, kubeconfig))
+
+	po := clientcmd.NewDefaultPathOptions()
+	po.LoadingRules.ExplicitPath = kubeconfig
+	o := &ContextOptions{PahtOptions: po}
+
+	// the namespaces are read from the cache, so the cluster is not requested
+	cache := completionCachePath(po, "gke_prod", []string{"namespaces"})
+	assert.NoError(t, writeCacheFile(cache, "default\nkube-public\nkube-system"))
+
+	tests := []struct {
+		TestName   string
+		ToComplete string
+		Expected   []string
+	}{
+		{"all", "", []string{"gke_prod", "minikube", "prod"}},
+		{"prefix", "m", []string{"minikube"}},
+		{"namespaces of an alias", "prod:kube-", []string{"prod:kube-public", "prod:kube-system"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			values, directive := o.completeContextArg(&cobra.Command{}, nil, tt.ToComplete)
+			assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+			assert.Equal(t, tt.Expected, values)
+		})
+	}
+}
@@ -3,11 +3,17 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/leocomelli/kw/pkg/common"
 	"github.com/leocomelli/kw/pkg/config"
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/leocomelli/kw/pkg/kubernetes"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
@@ -19,12 +25,28 @@ var (
 		# List all contexts.
 		kw ctx
 
-		# List all contexts in ps output format with more information (such as namespace).
+		# List all contexts in ps output format with more information (such as namespace and source file).
 		kw ctx -o wide
 
+		# List the contexts of a specific kubeconfig file
+		kw --kubeconfig ~/.kube/staging.yaml ctx
+
+		# List all contexts in JSON or using custom columns
+		kw ctx -o json
+		kw ctx -o custom-columns=NAME:.name,SERVER:.server,AUTH:.authType
+
+		# Check whether the API server of each context is reachable
+		kw ctx --check
+
+		# List the most recently used contexts first
+		kw ctx --sort-by=recent
+
 		# Modify the current context using the interactive mode
 		kw ctx -i
 
+		# Modify the current context and its namespace using the interactive mode
+		kw ctx -i --ns
+
 		# Modify the current context
 		kw ctx minikube
 
@@ -34,60 +56,161 @@ var (
 		# Modify the current context and its namespace
 		kw ctx minikube:kube-system
 
-		# Modify the current context and switch to the previous namespace
+		# Modify the current context and switch to the previous namespace used in it
 		kw ctx minikube:-
+
+		# Modify the current context and switch to the last namespace used in it
+		kw ctx minikube --restore-namespace
+
+		# List the recently used contexts
+		kw ctx --history
+
+		# Switch to the context used three steps ago
+		kw ctx @3
+
+		# Modify the current context and switch to the namespace used two steps ago
+		kw ctx minikube:@2
+
+		# Modify the current context only for the current shell
+		eval "$(kw ctx --session minikube)"
+
+		# Modify the current context for 15 minutes, then switch back to the current one
+		kw ctx prod --for 15m
+
+		# Rename, copy or delete a context
+		kw ctx rename minikube local
+		kw ctx copy local local-system --namespace kube-system
+		kw ctx delete local-system --prune
+
+		# Merge the contexts of a kubeconfig file
+		kw ctx import ~/Downloads/staging.yaml
+
+		# Export a context as a self-contained kubeconfig
+		kw ctx export minikube -o minikube.yaml
+
+		# Define an alias and use it to modify the current context
+		kw ctx alias prod gke_project-1234_europe-west1_prod-main
+		kw ctx prod:kube-system
+
+		# Tag a context and list only the contexts matching a selector
+		kw ctx tag minikube env=dev team=payments
+		kw ctx -l env=dev -o wide
+
+		# Highlight a context and ask for confirmation before switching to it
+		kw ctx protect prod --require-confirm
+
+		# Run commands before and after switching to a context, using the hooks of ~/.kube/.kw.yml:
+		#   hooks:
+		#   - selector: provider=eks
+		#     pre: aws sso login --profile "$KW_CONTEXT"
+		#     timeout: 2m
+		kw ctx eks-prod
+
+		# Report missing clusters, users, files and other problems of the kubeconfig
+		kw ctx lint
+
+		# Show when the client and CA certificates of the contexts expire
+		kw ctx certs --warn-days 30
 		`)
 )
 
 // ContextOptions contains the input to the get command.
 type ContextOptions struct {
-	Output         string
-	NoHeaders      bool
-	Interactive    bool
-	Config         *clientcmdapi.Config
-	PahtOptions    *clientcmd.PathOptions
-	KubeWideConfig *config.KubeWideConfig
+	Output           string
+	Selector         string
+	SortBy           string
+	NoHeaders        bool
+	Interactive      bool
+	Namespace        bool
+	RestoreNamespace bool
+	History          bool
+	Session          bool
+	Check            bool
+	Timeout          time.Duration
+	Parallel         int
+	For              time.Duration
+	Config           *clientcmdapi.Config
+	PahtOptions      *clientcmd.PathOptions
+	KubeWideConfig   *config.KubeWideConfig
+
+	session *kubeconfig.Session
+	// confirmed skips the confirmation of the protected contexts
+	confirmed bool
+	// renamed maps the renamed contexts to their new name
+	renamed map[string]string
 
 	genericclioptions.IOStreams
 }
 
-func newContextOptions(s genericclioptions.IOStreams) *ContextOptions {
-	configAccess := clientcmd.NewDefaultPathOptions()
-	c, err := configAccess.GetStartingConfig()
-	if err != nil {
+func newContextOptions(s genericclioptions.IOStreams, configAccess *clientcmd.PathOptions) *ContextOptions {
+	return &ContextOptions{
+		PahtOptions: configAccess,
+		session:     kubeconfig.CurrentSession(),
+		IOStreams:   s,
+	}
+}
+
+// complete loads the kubeconfig and the internal file, it must be
+// called after the flags are parsed to honor the --kubeconfig flag
+func (o *ContextOptions) complete() {
+	if err := o.load(); err != nil {
 		fmt.Println(err)
 		os.Exit(PreFlightExitCode)
 	}
+}
 
-	kw, err := config.NewKubeWideConfig()
+func (o *ContextOptions) load() error {
+	c, err := o.PahtOptions.GetStartingConfig()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(PreFlightExitCode)
+		return err
 	}
 
-	return &ContextOptions{
-		Config:         c,
-		PahtOptions:    configAccess,
-		KubeWideConfig: kw,
-		IOStreams:      s,
+	kw, err := config.NewKubeWideConfig()
+	if err != nil {
+		return err
 	}
+	kw.MigrateNamespaceHistory(c.CurrentContext)
+
+	o.Config = c
+	o.KubeWideConfig = kw
+
+	return nil
 }
 
 // NewCmdContext creates a command object for the context actions
-func NewCmdContext(streams genericclioptions.IOStreams) *cobra.Command {
-	o := newContextOptions(streams)
+func NewCmdContext(streams genericclioptions.IOStreams, configAccess *clientcmd.PathOptions) *cobra.Command {
+	o := newContextOptions(streams, configAccess)
 
 	cmd := &cobra.Command{
-		Use:     "ctx",
-		Aliases: []string{"c", "context"},
-		Short:   "Manage the context and namespace",
-		Args:    cobra.MaximumNArgs(1),
-		Example: getExample,
+		Use:               "ctx",
+		Aliases:           []string{"c", "context"},
+		Short:             "Manage the context and namespace",
+		Args:              cobra.MaximumNArgs(1),
+		Example:           getExample,
+		ValidArgsFunction: o.completeContextArg,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			o.complete()
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			l := len(args)
 
+			if o.History {
+				printHistory(o.Out, "CONTEXT", o.KubeWideConfig.History.Contexts, o.NoHeaders)
+				return nil
+			}
+
+			if o.For < 0 {
+				return fmt.Errorf("--for must be a positive duration")
+			}
+
 			if l == 0 && !o.Interactive {
-				o.list()
+				if o.For > 0 {
+					return fmt.Errorf("--for requires a context")
+				}
+				err := o.list()
+				if err != nil {
+					return err
+				}
 			} else {
 				var (
 					context, namespace string
@@ -95,16 +218,26 @@ func NewCmdContext(streams genericclioptions.IOStreams) *cobra.Command {
 				)
 
 				if o.Interactive {
-					keys := make([]string, 0, len(o.Config.Contexts))
-					for k := range o.Config.Contexts {
-						keys = append(keys, k)
-					}
-					context, err = common.InteractiveMode(keys)
+					context, err = o.selectContext()
 					if err != nil {
 						return err
 					}
+
+					if o.Namespace || o.KubeWideConfig.Settings.ChainNamespace {
+						namespace, err = o.selectNamespace(context)
+						if err != nil {
+							return err
+						}
+					}
 				} else {
-					context, namespace = o.parseContextArg(args[0])
+					context, namespace, err = o.parseContextArg(args[0])
+					if err != nil {
+						return err
+					}
+				}
+
+				if o.Session {
+					return o.setSession(context, namespace)
 				}
 
 				return o.set(context, namespace)
@@ -114,25 +247,76 @@ func NewCmdContext(streams genericclioptions.IOStreams) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(newCmdContextRename(o))
+	cmd.AddCommand(newCmdContextCopy(o))
+	cmd.AddCommand(newCmdContextDelete(o))
+	cmd.AddCommand(newCmdContextImport(o))
+	cmd.AddCommand(newCmdContextExport(o))
+	cmd.AddCommand(newCmdContextAlias(o))
+	cmd.AddCommand(newCmdContextTag(o))
+	cmd.AddCommand(newCmdContextProtect(o))
+	cmd.AddCommand(newCmdContextLint(o))
+	cmd.AddCommand(newCmdContextCerts(o))
+
 	cmd.Flags().BoolVarP(&o.Interactive, "interactive", "i", o.Interactive, "Enable interactive mode.")
-	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "Does not print the headers.")
-	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output in the plain-text format with any additional information.")
+	cmd.Flags().BoolVar(&o.Namespace, "ns", o.Namespace, "Select the namespace after the context in the interactive mode.")
+	cmd.Flags().BoolVar(&o.RestoreNamespace, "restore-namespace", o.RestoreNamespace, "Switch to the last namespace used in the context when no namespace is given.")
+	cmd.PersistentFlags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "Does not print the headers.")
+	cmd.Flags().BoolVar(&o.History, "history", o.History, "List the recently used contexts.")
+	cmd.Flags().BoolVar(&o.Session, "session", o.Session, "Modify the context only for the current shell and print the commands to export it.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: wide|json|yaml|name|jsonpath=...|custom-columns=...")
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", "name", "Sort the contexts by name, recent or frequency.")
+	cmd.Flags().BoolVar(&o.Check, "check", o.Check, "Check whether the API server of each context is reachable.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", defaultCheckTimeout, "Timeout of the reachability check of each context.")
+	cmd.Flags().IntVar(&o.Parallel, "parallel", defaultCheckParallel, "Maximum number of contexts checked at the same time.")
+	cmd.Flags().DurationVar(&o.For, "for", o.For, "Switch back to the current context once the duration has passed, e.g. --for 15m.")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter the contexts by their tags, e.g. -l env=prod.")
 
 	return cmd
 }
 
-func (o *ContextOptions) parseContextArg(name string) (string, string) {
+// parseContextArg returns the context and the namespace of the argument,
+// resolving the aliases and the previous and history identifiers
+func (o *ContextOptions) parseContextArg(name string) (string, string, error) {
 	if name == PreviousIdentifier {
-		return o.KubeWideConfig.PreviousContext(), ""
+		ctx := o.KubeWideConfig.PreviousContext()
+		if ctx == "" {
+			return "", "", fmt.Errorf("no previous context")
+		}
+		return ctx, "", nil
+	}
+
+	if n, ok := parseHistoryArg(name); ok {
+		ctx := o.KubeWideConfig.HistoryContext(n)
+		if ctx == "" {
+			return "", "", fmt.Errorf("context not found in the history: %s%d", HistoryIdentifier, n)
+		}
+		return ctx, "", nil
 	}
 
 	if params := strings.Split(name, ":"); len(params) >= 2 {
+		// the namespaces are looked up by the name of the context
+		context, err := o.resolveContext(o.KubeWideConfig.ResolveAlias(params[0]))
+		if err != nil {
+			return "", "", err
+		}
 		if params[1] == PreviousIdentifier {
-			return params[0], o.KubeWideConfig.PreviousNamespace()
+			ns := o.KubeWideConfig.Context(context).PreviousNamespace
+			if ns == "" {
+				return "", "", fmt.Errorf("no previous namespace in the context: %s", context)
+			}
+			return context, ns, nil
+		}
+		if n, ok := parseHistoryArg(params[1]); ok {
+			ns := o.KubeWideConfig.HistoryNamespace(context, n)
+			if ns == "" {
+				return "", "", fmt.Errorf("namespace not found in the history of the context %s: %s%d", context, HistoryIdentifier, n)
+			}
+			return context, ns, nil
 		}
-		return params[0], params[1]
+		return context, params[1], nil
 	}
-	return name, ""
+	return o.KubeWideConfig.ResolveAlias(name), "", nil
 }
 
 func (o *ContextOptions) isWide() bool {
@@ -140,31 +324,152 @@ func (o *ContextOptions) isWide() bool {
 }
 
 func (o *ContextOptions) set(ctx, ns string) error {
+	ctx, err := o.resolveContext(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Preserve information about the current context to write it
 	// to the internal file. In the future, we can use this information
 	// to define to previous context and namespace.
+	newContext := o.Config.Contexts[ctx]
+
+	if ns == "" && (o.RestoreNamespace || o.KubeWideConfig.Settings.RestoreNamespace) {
+		ns = o.KubeWideConfig.Context(ctx).LastNamespace
+		// a context without namespace is recorded as the default one,
+		// it is left without namespace
+		if ns == config.DefaultNamespace && newContext.Namespace == "" {
+			ns = ""
+		}
+	}
+
 	previousContext, ok := o.Config.Contexts[o.Config.CurrentContext]
 	if ok {
 		if o.Config.CurrentContext != ctx {
-			o.KubeWideConfig.SetPreviousContext(o.Config.CurrentContext)
+			o.KubeWideConfig.SetPreviousContext(o.Config.CurrentContext, ctx)
 		}
 
-		if o.Config.Contexts[o.Config.CurrentContext].Namespace != ns {
+		// without a namespace the context keeps its own one
+		newNamespace := ns
+		if newNamespace == "" {
+			newNamespace = newContext.Namespace
+		}
+		if previousContext.Namespace != newNamespace {
 			o.KubeWideConfig.SetPreviousNamespace(previousContext.Namespace)
 		}
 	}
 
-	newContext, ok := o.Config.Contexts[ctx]
-	if !ok {
-		return fmt.Errorf("context not found: %s", ctx)
+	if err := o.confirmProtected(ctx, ns); err != nil {
+		return err
+	}
+
+	hooks, err := o.KubeWideConfig.ContextHooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	hookNamespace := ns
+	if hookNamespace == "" {
+		hookNamespace = newContext.Namespace
 	}
+	if hookNamespace == "" {
+		hookNamespace = config.DefaultNamespace
+	}
+
+	// A failing pre hook, e.g. an expired login, aborts the switch
+	if err := runHooks(o.IOStreams, hooks, hookPre, ctx, hookNamespace); err != nil {
+		return err
+	}
+
+	o.setExpiry(ctx, time.Now())
 
 	o.Config.CurrentContext = ctx
+	previousNamespace := newContext.Namespace
 	if ns != "" {
 		newContext.Namespace = ns
 	}
+	o.KubeWideConfig.SetContextNamespace(ctx, previousNamespace, newContext.Namespace)
+	o.KubeWideConfig.RecordUsage(ctx, newContext.Namespace, time.Now())
 
-	err := clientcmd.ModifyConfig(o.PahtOptions, *o.Config, true)
+	if err := o.write(); err != nil {
+		return err
+	}
+
+	// the context has already been modified, so a failing
+	// post hook is only reported
+	if err := runHooks(o.IOStreams, hooks, hookPost, ctx, hookNamespace); err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: %v\n", err)
+	}
+
+	return nil
+}
+
+// resolveContext returns the context whose name is the query, otherwise
+// the only context matching the query as a substring or a glob pattern.
+// When several contexts match, they are displayed in the interactive
+// mode if a terminal is available.
+func (o *ContextOptions) resolveContext(query string) (string, error) {
+	// an empty query would match every context
+	if query == "" {
+		return "", fmt.Errorf("context name is required")
+	}
+
+	if _, ok := o.Config.Contexts[query]; ok {
+		return query, nil
+	}
+
+	names := make([]string, 0, len(o.Config.Contexts))
+	for k := range o.Config.Contexts {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	candidates := matchContexts(names, query)
+	switch {
+	case len(candidates) == 0:
+		return "", fmt.Errorf("context not found: %s", query)
+	case len(candidates) == 1:
+		return candidates[0], nil
+	case !common.IsTerminal(o.In):
+		return "", fmt.Errorf("context %q is ambiguous, it matches: %s", query, strings.Join(candidates, ", "))
+	}
+
+	config.SortByUsage(candidates, config.SortFrecency, time.Now(), o.KubeWideConfig.ContextUsage)
+
+	return common.InteractiveMode(candidates,
+		common.WithPreview(o.previewContext),
+		common.WithHeader(fmt.Sprintf("Contexts matching %q", query)))
+}
+
+// matchContexts returns the names containing the query, a query with
+// the wildcards * or ? is matched against the whole name instead
+func matchContexts(names []string, query string) []string {
+	var match func(string) bool
+	if strings.ContainsAny(query, "*?") {
+		pattern := regexp.QuoteMeta(query)
+		pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+		pattern = strings.ReplaceAll(pattern, `\?`, ".")
+		re := regexp.MustCompile("^" + pattern + "$")
+		match = re.MatchString
+	} else {
+		match = func(name string) bool {
+			return strings.Contains(name, query)
+		}
+	}
+
+	var candidates []string
+	for _, n := range names {
+		if match(n) {
+			candidates = append(candidates, n)
+		}
+	}
+
+	return candidates
+}
+
+// write persists the kubeconfig changes and then the internal file
+func (o *ContextOptions) write() error {
+	err := modifyConfig(o.PahtOptions, o.Config, o.session, o.renamed)
 	if err != nil {
 		return fmt.Errorf("error when modifying the current context: %w", err)
 	}
@@ -179,27 +484,153 @@ func (o *ContextOptions) set(ctx, ns string) error {
 	return nil
 }
 
-func (o *ContextOptions) list() {
+// setSession modifies the context in a session kubeconfig, creating
+// a new session when the shell is not using one yet
+func (o *ContextOptions) setSession(ctx, ns string) error {
+	if o.session == nil {
+		// the session belongs to the shell that evaluates the output
+		s, err := kubeconfig.NewSession(sessionFiles(o.PahtOptions, nil), os.Getppid())
+		if err != nil {
+			return err
+		}
+		o.session = s
+	}
+
+	if err := o.set(ctx, ns); err != nil {
+		return err
+	}
+
+	printSessionEnv(o.Out, o.session)
+
+	return nil
+}
+
+// contextNames returns the names of the contexts matching the selector
+func (o *ContextOptions) contextNames() ([]string, error) {
+	selector, err := labels.Parse(o.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+
+	names := make([]string, 0, len(o.Config.Contexts))
+	for k := range o.Config.Contexts {
+		if selector.Matches(labels.Set(o.KubeWideConfig.Context(k).Tags)) {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// selectContext displays the contexts and their aliases in the
+// interactive mode and returns the selected context
+func (o *ContextOptions) selectContext() (string, error) {
+	names, err := o.contextNames()
+	if err != nil {
+		return "", err
+	}
+	config.SortByUsage(names, config.SortFrecency, time.Now(), o.KubeWideConfig.ContextUsage)
+
+	keys := make([]string, 0, len(names)+len(o.KubeWideConfig.Aliases))
+	for _, k := range names {
+		keys = append(keys, k)
+		keys = append(keys, o.KubeWideConfig.ContextAliases(k)...)
+	}
+
+	context, err := common.InteractiveMode(keys, common.WithPreview(o.previewContext))
+	if err != nil {
+		return "", err
+	}
+
+	return o.KubeWideConfig.ResolveAlias(context), nil
+}
+
+// selectNamespace connects to the cluster of the context and displays
+// its namespaces in the interactive mode
+func (o *ContextOptions) selectNamespace(context string) (string, error) {
+	k, err := kubernetes.NewKubernetesForContext(o.PahtOptions.LoadingRules, context)
+	if err != nil {
+		return "", err
+	}
+
+	return selectNamespace(k, o.PahtOptions.LoadingRules, o.KubeWideConfig, context)
+}
+
+func (o *ContextOptions) list() error {
 	var headers []string
 	if !o.NoHeaders {
-		headers = []string{"  CONTEXT", "NAMESPACE"}
+		headers = []string{"  CONTEXT", "NAMESPACE", "ALIAS", "TAGS", "SOURCE"}
 		if !o.isWide() {
 			headers = headers[:1]
 		}
 	}
 
-	var data [][]string
-	for k, v := range o.Config.Contexts {
+	names, err := o.contextNames()
+	if err != nil {
+		return err
+	}
+
+	switch o.SortBy {
+	case "name":
+	case config.SortRecent, config.SortFrequency:
+		config.SortByUsage(names, o.SortBy, time.Now(), o.KubeWideConfig.ContextUsage)
+	default:
+		return fmt.Errorf("invalid sort criteria %q, allowed values: name, recent, frequency", o.SortBy)
+	}
+
+	if o.isStructured() {
+		return o.printStructured(names)
+	}
+
+	if o.Output != "" && !o.isWide() {
+		return fmt.Errorf("invalid output format: %s", o.Output)
+	}
+
+	var probes map[string]*kubernetes.Probe
+	if o.Check {
+		var err error
+		probes, err = o.probe(names)
+		if err != nil {
+			return err
+		}
+		if !o.NoHeaders {
+			headers = append(headers, "STATUS", "VERSION", "LATENCY", "AUTH")
+			if o.isWide() {
+				headers = append(headers, "ERROR")
+			}
+		}
+	}
+
+	var (
+		data   [][]string
+		colors []common.PrintFn
+	)
+	for _, k := range names {
+		colors = append(colors, protectedColor(o.KubeWideConfig, k))
+
+		v := o.Config.Contexts[k]
 		current := " "
 		if k == o.Config.CurrentContext {
 			current = "*"
 		}
+
+		row := []string{fmt.Sprintf("%s %s", current, k)}
 		if o.isWide() {
-			data = append(data, []string{fmt.Sprintf("%s %s", current, k), v.Namespace})
-			continue
+			aliases := strings.Join(o.KubeWideConfig.ContextAliases(k), ",")
+			tags := labels.Set(o.KubeWideConfig.Context(k).Tags).String()
+			row = append(row, v.Namespace, aliases, tags, v.LocationOfOrigin)
+		}
+		if o.Check {
+			row = append(row, probeColumns(probes[k])...)
+			if o.isWide() {
+				row = append(row, probeError(probes[k]))
+			}
 		}
-		data = append(data, []string{fmt.Sprintf("%s %s", current, k)})
+		data = append(data, row)
 	}
 
-	common.TabPrint(os.Stdout, headers, data)
+	common.TabPrintFn(os.Stdout, headers, data, colors)
+
+	return nil
 }
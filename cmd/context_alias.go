@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leocomelli/kw/pkg/common"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	aliasExamples = templates.Examples(`
+		# List all aliases
+		kw ctx alias
+
+		# Define prod as an alias of a context
+		kw ctx alias prod gke_project-1234_europe-west1_prod-main
+
+		# Remove an alias
+		kw ctx alias -d prod
+		`)
+)
+
+func newCmdContextAlias(o *ContextOptions) *cobra.Command {
+	var remove bool
+
+	cmd := &cobra.Command{
+		Use:     "alias [NAME [CONTEXT]]",
+		Short:   "Manage the context aliases",
+		Args:    cobra.MaximumNArgs(2),
+		Example: aliasExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case len(args) == 0:
+				o.listAliases()
+				return nil
+			case remove:
+				if _, ok := o.KubeWideConfig.Aliases[args[0]]; !ok {
+					return fmt.Errorf("alias not found: %s", args[0])
+				}
+				o.KubeWideConfig.RemoveAlias(args[0])
+			case len(args) == 2:
+				if err := o.validateAlias(args[0], args[1]); err != nil {
+					return err
+				}
+				o.KubeWideConfig.SetAlias(args[0], args[1])
+			default:
+				return fmt.Errorf("the context of the alias %s is required", args[0])
+			}
+
+			return o.KubeWideConfig.Write()
+		},
+	}
+
+	cmd.Flags().BoolVarP(&remove, "delete", "d", remove, "Remove the alias.")
+
+	return cmd
+}
+
+func (o *ContextOptions) validateAlias(alias, context string) error {
+	if strings.Contains(alias, ":") || alias == PreviousIdentifier || strings.HasPrefix(alias, HistoryIdentifier) {
+		return fmt.Errorf("invalid alias: %s", alias)
+	}
+
+	if _, ok := o.Config.Contexts[alias]; ok {
+		return fmt.Errorf("a context with the same name already exists: %s", alias)
+	}
+
+	if _, ok := o.Config.Contexts[context]; !ok {
+		return fmt.Errorf("context not found: %s", context)
+	}
+
+	return nil
+}
+
+func (o *ContextOptions) listAliases() {
+	var headers []string
+	if !o.NoHeaders {
+		headers = []string{"ALIAS", "CONTEXT"}
+	}
+
+	aliases := make([]string, 0, len(o.KubeWideConfig.Aliases))
+	for a := range o.KubeWideConfig.Aliases {
+		aliases = append(aliases, a)
+	}
+	sort.Strings(aliases)
+
+	var data [][]string
+	for _, a := range aliases {
+		data = append(data, []string{a, o.KubeWideConfig.Aliases[a]})
+	}
+
+	common.TabPrint(o.Out, headers, data)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/leocomelli/kw/pkg/common"
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const defaultWarnDays = 30
+
+var (
+	certsExamples = templates.Examples(`
+		# Show the expiration of the certificates of all contexts
+		kw ctx certs
+
+		# Show the certificates of a context and fail when any of them expires within 60 days
+		kw ctx certs prod --warn-days 60
+		`)
+)
+
+func newCmdContextCerts(o *ContextOptions) *cobra.Command {
+	warnDays := defaultWarnDays
+
+	cmd := &cobra.Command{
+		Use:     "certs [NAME ...]",
+		Short:   "Show the expiration of the client and CA certificates of the contexts",
+		Example: certsExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := make([]string, 0, len(args))
+			for _, a := range args {
+				name := o.KubeWideConfig.ResolveAlias(a)
+				if _, ok := o.Config.Contexts[name]; !ok {
+					return fmt.Errorf("context not found: %s", name)
+				}
+				names = append(names, name)
+			}
+
+			if len(names) == 0 {
+				var err error
+				names, err = o.contextNames()
+				if err != nil {
+					return err
+				}
+			}
+
+			var certs []kubeconfig.Certificate
+			for _, n := range names {
+				certs = append(certs, kubeconfig.ContextCertificates(o.Config, n)...)
+			}
+
+			failed := o.printCertificates(certs, warnDays, time.Now())
+			if failed > 0 {
+				// the certificates were already reported, the usage does not help
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d certificate(s) invalid or expiring within %d days", failed, warnDays)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&warnDays, "warn-days", warnDays, "Highlight the certificates expiring within this number of days and exit with a non-zero code.")
+
+	return cmd
+}
+
+// printCertificates writes the certificates and returns how many of
+// them are invalid, expired or expiring within the warning period
+func (o *ContextOptions) printCertificates(certs []kubeconfig.Certificate, warnDays int, now time.Time) int {
+	var headers []string
+	if !o.NoHeaders {
+		headers = []string{"CONTEXT", "TYPE", "SUBJECT", "ISSUER", "EXPIRES", "DAYS"}
+	}
+
+	pc := common.NewPrintColor()
+	red, _ := pc.GetByName("red")
+	yellow, _ := pc.GetByName("yellow")
+
+	var (
+		failed int
+		data   [][]string
+		colors []common.PrintFn
+	)
+	for _, c := range certs {
+		if c.Err != nil {
+			failed++
+			colors = append(colors, red)
+			data = append(data, []string{c.Context, c.Type, fmt.Sprintf("<error: %v>", c.Err), "", "", ""})
+			continue
+		}
+
+		days := daysLeft(c.NotAfter, now)
+		switch {
+		case days < 0:
+			failed++
+			colors = append(colors, red)
+		case days < warnDays:
+			failed++
+			colors = append(colors, yellow)
+		default:
+			colors = append(colors, nil)
+		}
+
+		data = append(data, []string{c.Context, c.Type, c.Subject, c.Issuer, c.NotAfter.Format("2006-01-02"), fmt.Sprintf("%d", days)})
+	}
+
+	common.TabPrintFn(o.Out, headers, data, colors)
+
+	return failed
+}
+
+// daysLeft returns the number of whole days until the expiration,
+// it is negative when the certificate has already expired
+func daysLeft(notAfter, now time.Time) int {
+	return int(math.Floor(notAfter.Sub(now).Hours() / 24))
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leocomelli/kw/pkg/kubernetes"
+)
+
+const (
+	defaultCheckTimeout  = 5 * time.Second
+	defaultCheckParallel = 8
+)
+
+// probe checks the API servers of the contexts in parallel
+func (o *ContextOptions) probe(names []string) (map[string]*kubernetes.Probe, error) {
+	if o.Parallel < 1 {
+		return nil, fmt.Errorf("--parallel must be greater than zero")
+	}
+
+	probes := make(map[string]*kubernetes.Probe, len(names))
+	for _, p := range kubernetes.ProbeContexts(o.Config, names, o.Timeout, o.Parallel) {
+		probes[p.Context] = p
+	}
+
+	return probes, nil
+}
+
+// probeColumns returns the STATUS, VERSION, LATENCY and AUTH columns
+func probeColumns(p *kubernetes.Probe) []string {
+	if !p.Reachable {
+		return []string{"unreachable", "-", "-", "-"}
+	}
+
+	version := p.Version
+	if version == "" {
+		version = "-"
+	}
+
+	return []string{"reachable", version, p.Latency.Round(time.Millisecond).String(), p.Auth}
+}
+
+// probeError returns the ERROR column, the error is kept in a single line
+func probeError(p *kubernetes.Probe) string {
+	if p.Err == nil {
+		return "-"
+	}
+	return strings.Join(strings.Fields(p.Err.Error()), " ")
+}
+
+func healthInfo(p *kubernetes.Probe) *HealthInfo {
+	h := &HealthInfo{
+		Reachable: p.Reachable,
+		Version:   p.Version,
+		LatencyMs: p.Latency.Milliseconds(),
+		Auth:      p.Auth,
+	}
+	if p.Err != nil {
+		h.Error = p.Err.Error()
+	}
+
+	return h
+}
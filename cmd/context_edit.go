@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	renameExamples = templates.Examples(`
+		# Rename the context gke_project-1234_europe-west1_prod-main to prod
+		kw ctx rename gke_project-1234_europe-west1_prod-main prod
+		`)
+
+	copyExamples = templates.Examples(`
+		# Create the context minikube-system using the kube-system namespace
+		kw ctx copy minikube minikube-system --namespace kube-system
+		`)
+
+	deleteExamples = templates.Examples(`
+		# Delete the context minikube
+		kw ctx delete minikube
+
+		# Delete the context minikube and the clusters and users no longer used
+		kw ctx delete minikube --prune
+		`)
+)
+
+func newCmdContextRename(o *ContextOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rename OLD_NAME NEW_NAME",
+		Aliases: []string{"mv"},
+		Short:   "Rename a context",
+		Args:    cobra.ExactArgs(2),
+		Example: renameExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := kubeconfig.RenameContext(o.Config, args[0], args[1]); err != nil {
+				return err
+			}
+			o.KubeWideConfig.RenameContext(args[0], args[1])
+			o.renamed = map[string]string{args[0]: args[1]}
+
+			if err := o.write(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(o.Out, "Context %q renamed to %q.\n", args[0], args[1])
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newCmdContextCopy(o *ContextOptions) *cobra.Command {
+	var namespace string
+
+	cmd := &cobra.Command{
+		Use:     "copy SOURCE DESTINATION",
+		Aliases: []string{"cp"},
+		Short:   "Create a new context from an existing one",
+		Args:    cobra.ExactArgs(2),
+		Example: copyExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := kubeconfig.CopyContext(o.Config, args[0], args[1], namespace); err != nil {
+				return err
+			}
+
+			if err := o.write(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(o.Out, "Context %q copied to %q.\n", args[0], args[1])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the new context.")
+
+	return cmd
+}
+
+func newCmdContextDelete(o *ContextOptions) *cobra.Command {
+	var prune bool
+
+	cmd := &cobra.Command{
+		Use:     "delete NAME",
+		Aliases: []string{"rm"},
+		Short:   "Delete a context",
+		Args:    cobra.ExactArgs(1),
+		Example: deleteExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := kubeconfig.DeleteContext(o.Config, args[0]); err != nil {
+				return err
+			}
+			o.KubeWideConfig.RemoveContext(args[0])
+
+			var clusters, users []string
+			if prune {
+				clusters, users = kubeconfig.PruneOrphans(o.Config)
+			}
+
+			if err := o.write(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(o.Out, "Context %q deleted.\n", args[0])
+			for _, c := range clusters {
+				fmt.Fprintf(o.Out, "Cluster %q deleted.\n", c)
+			}
+			for _, u := range users {
+				fmt.Fprintf(o.Out, "User %q deleted.\n", u)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&prune, "prune", prune, "Delete the clusters and users that are no longer referenced by any context.")
+
+	return cmd
+}
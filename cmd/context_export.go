@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	exportExamples = templates.Examples(`
+		# Print a kubeconfig containing only the context minikube
+		kw ctx export minikube
+
+		# Write the context minikube to a file
+		kw ctx export minikube -o minikube.yaml
+
+		# Share only the cluster definition, without the user credentials
+		kw ctx export minikube --no-credentials
+		`)
+)
+
+func newCmdContextExport(o *ContextOptions) *cobra.Command {
+	var (
+		output        string
+		noCredentials bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "export NAME",
+		Short:   "Export a context as a self-contained kubeconfig",
+		Args:    cobra.ExactArgs(1),
+		Example: exportExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := kubeconfig.Export(o.Config, args[0], !noCredentials)
+			if err != nil {
+				return err
+			}
+
+			if output != "" {
+				if err := clientcmd.WriteToFile(*c, output); err != nil {
+					return fmt.Errorf("error writing the kubeconfig file: %w", err)
+				}
+				return nil
+			}
+
+			b, err := clientcmd.Write(*c)
+			if err != nil {
+				return fmt.Errorf("error encoding the kubeconfig: %w", err)
+			}
+
+			_, err = o.Out.Write(b)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", output, "Write the kubeconfig to the given file instead of the standard output.")
+	cmd.Flags().BoolVar(&noCredentials, "no-credentials", noCredentials, "Do not include the user credentials.")
+
+	return cmd
+}
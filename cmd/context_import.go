@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	importExamples = templates.Examples(`
+		# Import the contexts, clusters and users of a kubeconfig file
+		kw ctx import ~/Downloads/staging.yaml
+
+		# Show what would be imported, replacing the entries with the same name
+		kw ctx import ~/Downloads/staging.yaml --strategy overwrite --dry-run
+		`)
+)
+
+func newCmdContextImport(o *ContextOptions) *cobra.Command {
+	var (
+		strategy = string(kubeconfig.MergeRename)
+		dryRun   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "import FILE",
+		Short:   "Merge the contexts, clusters and users of a kubeconfig file",
+		Args:    cobra.ExactArgs(1),
+		Example: importExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := kubeconfig.ParseMergeStrategy(strategy)
+			if err != nil {
+				return err
+			}
+
+			src, err := clientcmd.LoadFromFile(args[0])
+			if err != nil {
+				return fmt.Errorf("error reading the kubeconfig file: %w", err)
+			}
+
+			if err := clientcmd.ResolveLocalPaths(src); err != nil {
+				return fmt.Errorf("error resolving the kubeconfig paths: %w", err)
+			}
+
+			current := o.Config.DeepCopy()
+			changes := kubeconfig.Merge(o.Config, src, st)
+			for _, c := range changes {
+				fmt.Fprintln(o.Out, c)
+			}
+
+			if dryRun {
+				diff, err := kubeconfig.Diff(current, o.Config, "kubeconfig", "kubeconfig (imported "+args[0]+")")
+				if err != nil {
+					return err
+				}
+				if diff != "" {
+					fmt.Fprintf(o.Out, "\n%s", diff)
+				}
+				return nil
+			}
+
+			return o.write()
+		},
+	}
+
+	cmd.Flags().StringVar(&strategy, "strategy", strategy, "How name collisions are resolved: rename, overwrite or skip.")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", dryRun, "Only print the changes and the resulting diff of the kubeconfig, credentials are redacted.")
+
+	return cmd
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/leocomelli/kw/pkg/common"
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	lintExamples = templates.Examples(`
+		# Report the problems found in the kubeconfig files
+		kw ctx lint
+
+		# Check a kubeconfig file in a CI pipeline, the exit code is non-zero when errors are found
+		kw --kubeconfig ./kubeconfig.yaml ctx lint --no-headers
+		`)
+)
+
+// lintColors defines how the problems are highlighted by severity
+var lintColors = map[string]string{
+	kubeconfig.SeverityError:   "red",
+	kubeconfig.SeverityWarning: "yellow",
+}
+
+func newCmdContextLint(o *ContextOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "lint",
+		Short:   "Report the problems found in the kubeconfig",
+		Args:    cobra.NoArgs,
+		Example: lintExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			problems := kubeconfig.Lint(o.Config)
+			if len(problems) == 0 {
+				fmt.Fprintln(o.Out, "No problems found")
+				return nil
+			}
+
+			errors := o.printProblems(problems)
+			if errors > 0 {
+				// the problems were already reported, the usage does not help
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d error(s) found in the kubeconfig", errors)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// printProblems writes the problems and returns how many errors were found
+func (o *ContextOptions) printProblems(problems []kubeconfig.Problem) int {
+	var headers []string
+	if !o.NoHeaders {
+		headers = []string{"SEVERITY", "KIND", "NAME", "MESSAGE"}
+	}
+
+	pc := common.NewPrintColor()
+
+	var (
+		errors int
+		data   [][]string
+		colors []common.PrintFn
+	)
+	for _, p := range problems {
+		if p.Severity == kubeconfig.SeverityError {
+			errors++
+		}
+
+		color, _ := pc.GetByName(lintColors[p.Severity])
+		colors = append(colors, color)
+		data = append(data, []string{p.Severity, p.Kind, p.Name, p.Message})
+	}
+
+	common.TabPrintFn(o.Out, headers, data, colors)
+
+	return errors
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/leocomelli/kw/pkg/common"
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/leocomelli/kw/pkg/kubernetes"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	jsonPathPrefix      = "jsonpath="
+	customColumnsPrefix = "custom-columns="
+)
+
+// ContextInfo describes a context in the structured output formats
+type ContextInfo struct {
+	Name      string            `json:"name" yaml:"name"`
+	Current   bool              `json:"current" yaml:"current"`
+	Cluster   string            `json:"cluster" yaml:"cluster"`
+	Server    string            `json:"server" yaml:"server"`
+	User      string            `json:"user" yaml:"user"`
+	AuthType  string            `json:"authType" yaml:"authType"`
+	Namespace string            `json:"namespace" yaml:"namespace"`
+	Aliases   []string          `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Protected bool              `json:"protected" yaml:"protected"`
+	Source    string            `json:"source,omitempty" yaml:"source,omitempty"`
+	Health    *HealthInfo       `json:"health,omitempty" yaml:"health,omitempty"`
+}
+
+// HealthInfo describes the result of the reachability check of a context
+type HealthInfo struct {
+	Reachable bool   `json:"reachable" yaml:"reachable"`
+	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
+	LatencyMs int64  `json:"latencyMs" yaml:"latencyMs"`
+	Auth      string `json:"auth" yaml:"auth"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ContextList contains the contexts in the structured output formats
+type ContextList struct {
+	Items []ContextInfo `json:"items" yaml:"items"`
+}
+
+// isStructured reports whether the output is printed by printStructured
+func (o *ContextOptions) isStructured() bool {
+	switch o.Output {
+	case "json", "yaml", "name":
+		return true
+	}
+	return strings.HasPrefix(o.Output, jsonPathPrefix) || strings.HasPrefix(o.Output, customColumnsPrefix)
+}
+
+func (o *ContextOptions) contextInfo(name string) ContextInfo {
+	ctx := o.Config.Contexts[name]
+	cc := o.KubeWideConfig.Context(name)
+
+	return ContextInfo{
+		Name:      name,
+		Current:   name == o.Config.CurrentContext,
+		Cluster:   ctx.Cluster,
+		Server:    kubeconfig.Server(o.Config, name),
+		User:      ctx.AuthInfo,
+		AuthType:  kubeconfig.AuthType(o.Config.AuthInfos[ctx.AuthInfo]),
+		Namespace: ctx.Namespace,
+		Aliases:   o.KubeWideConfig.ContextAliases(name),
+		Tags:      cc.Tags,
+		Protected: cc.Protected,
+		Source:    ctx.LocationOfOrigin,
+	}
+}
+
+// printStructured writes the contexts using the output format
+func (o *ContextOptions) printStructured(names []string) error {
+	var probes map[string]*kubernetes.Probe
+	if o.Check {
+		var err error
+		probes, err = o.probe(names)
+		if err != nil {
+			return err
+		}
+	}
+
+	list := ContextList{Items: make([]ContextInfo, 0, len(names))}
+	for _, n := range names {
+		info := o.contextInfo(n)
+		if p, ok := probes[n]; ok {
+			info.Health = healthInfo(p)
+		}
+		list.Items = append(list.Items, info)
+	}
+
+	switch {
+	case o.Output == "name":
+		for _, i := range list.Items {
+			fmt.Fprintln(o.Out, i.Name)
+		}
+		return nil
+	case o.Output == "json":
+		b, err := json.MarshalIndent(list, "", "    ")
+		if err != nil {
+			return fmt.Errorf("error encoding the contexts: %w", err)
+		}
+		fmt.Fprintln(o.Out, string(b))
+		return nil
+	case o.Output == "yaml":
+		b, err := yaml.Marshal(list)
+		if err != nil {
+			return fmt.Errorf("error encoding the contexts: %w", err)
+		}
+		_, err = o.Out.Write(b)
+		return err
+	case strings.HasPrefix(o.Output, jsonPathPrefix):
+		data, err := toGeneric(list)
+		if err != nil {
+			return err
+		}
+		out, err := evalJSONPath(strings.TrimPrefix(o.Output, jsonPathPrefix), data)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(o.Out, out)
+		return nil
+	default:
+		return o.printCustomColumns(strings.TrimPrefix(o.Output, customColumnsPrefix), list.Items)
+	}
+}
+
+// printCustomColumns writes the contexts using columns defined as
+// HEADER:JSONPATH separated by commas, e.g. NAME:.name,SERVER:.server
+func (o *ContextOptions) printCustomColumns(spec string, items []ContextInfo) error {
+	var headers, paths []string
+	for _, col := range strings.Split(spec, ",") {
+		parts := strings.SplitN(col, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid custom column, it must be HEADER:JSONPATH: %s", col)
+		}
+		headers = append(headers, parts[0])
+		paths = append(paths, parts[1])
+	}
+
+	var data [][]string
+	for _, i := range items {
+		item, err := toGeneric(i)
+		if err != nil {
+			return err
+		}
+
+		var row []string
+		for _, p := range paths {
+			v, err := evalJSONPath(p, item)
+			if err != nil {
+				return err
+			}
+			if v == "" {
+				v = "<none>"
+			}
+			row = append(row, v)
+		}
+		data = append(data, row)
+	}
+
+	if o.NoHeaders {
+		headers = nil
+	}
+	common.TabPrint(o.Out, headers, data)
+
+	return nil
+}
+
+// evalJSONPath executes the template, braces are added when the
+// template is a bare expression such as .name
+func evalJSONPath(tmpl string, data interface{}) (string, error) {
+	if !strings.Contains(tmpl, "{") {
+		tmpl = fmt.Sprintf("{%s}", tmpl)
+	}
+
+	jp := jsonpath.New("kw").AllowMissingKeys(true)
+	if err := jp.Parse(tmpl); err != nil {
+		return "", fmt.Errorf("error parsing the jsonpath %s: %w", tmpl, err)
+	}
+
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("error executing the jsonpath %s: %w", tmpl, err)
+	}
+
+	return buf.String(), nil
+}
+
+// toGeneric converts a value to the generic json representation,
+// so the jsonpath expressions use the json field names
+func toGeneric(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding the contexts: %w", err)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("error decoding the contexts: %w", err)
+	}
+
+	return data, nil
+}
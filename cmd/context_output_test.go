@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/leocomelli/kw/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestPrintStructured(t *testing.T) {
+
+	c := clientcmdapi.NewConfig()
+	c.Clusters["c1"] = &clientcmdapi.Cluster{Server: "https://c1"}
+	c.AuthInfos["u1"] = &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "aws"}}
+	c.AuthInfos["u2"] = &clientcmdapi.AuthInfo{Token: "t"}
+	c.Contexts["a"] = &clientcmdapi.Context{Cluster: "c1", AuthInfo: "u1", Namespace: "kube-system"}
+	c.Contexts["b"] = &clientcmdapi.Context{Cluster: "c1", AuthInfo: "u2"}
+	c.CurrentContext = "b"
+
+	tests := []struct {
+		TestName string
+		Output   string
+		Expected string
+	}{
+		{"name", "name", "a\nb\n"},
+		{"jsonpath", "jsonpath={.items[*].authType}", "exec token"},
+		{"custom columns", "custom-columns=NAME:.name,NS:.namespace,CURRENT:.current", "NAME\tNS\t\tCURRENT\na\tkube-system\tfalse\nb\t<none>\t\ttrue\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := &ContextOptions{
+				Output:         tt.Output,
+				Config:         c,
+				KubeWideConfig: &config.KubeWideConfig{},
+				IOStreams:      genericclioptions.IOStreams{Out: out},
+			}
+
+			assert.True(t, o.isStructured())
+			assert.NoError(t, o.printStructured([]string{"a", "b"}))
+			assert.Equal(t, tt.Expected, out.String())
+		})
+	}
+}
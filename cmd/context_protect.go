@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/leocomelli/kw/pkg/common"
+	"github.com/leocomelli/kw/pkg/config"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const defaultProtectedColor = "red"
+
+var (
+	protectExamples = templates.Examples(`
+		# Mark a context as protected
+		kw ctx protect prod
+
+		# Highlight the context in yellow and ask for confirmation before switching to it
+		kw ctx protect prod --color yellow --require-confirm
+
+		# Remove the protection of a context
+		kw ctx protect prod --disable
+		`)
+)
+
+func newCmdContextProtect(o *ContextOptions) *cobra.Command {
+	var (
+		color          = defaultProtectedColor
+		requireConfirm bool
+		disable        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "protect NAME",
+		Short:   "Mark a context as protected",
+		Args:    cobra.ExactArgs(1),
+		Example: protectExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := o.KubeWideConfig.ResolveAlias(args[0])
+			if _, ok := o.Config.Contexts[name]; !ok {
+				return fmt.Errorf("context not found: %s", name)
+			}
+
+			if disable {
+				o.KubeWideConfig.Unprotect(name)
+				return o.KubeWideConfig.Write()
+			}
+
+			if _, err := common.NewPrintColor().GetByName(color); err != nil {
+				return err
+			}
+
+			o.KubeWideConfig.Protect(name, color, requireConfirm)
+			return o.KubeWideConfig.Write()
+		},
+	}
+
+	cmd.Flags().StringVar(&color, "color", color, "Color used to highlight the context.")
+	cmd.Flags().BoolVar(&requireConfirm, "require-confirm", requireConfirm, "Ask for confirmation before switching to the context.")
+	cmd.Flags().BoolVar(&disable, "disable", disable, "Remove the protection of the context.")
+
+	return cmd
+}
+
+// protectedColor returns the print function used to highlight
+// a protected context, otherwise nil
+func protectedColor(kw *config.KubeWideConfig, name string) common.PrintFn {
+	cc := kw.Context(name)
+	if !cc.Protected {
+		return nil
+	}
+
+	fn, err := common.NewPrintColor().GetByName(cc.Color)
+	if err != nil {
+		fn, _ = common.NewPrintColor().GetByName(defaultProtectedColor)
+	}
+
+	return fn
+}
+
+// confirmProtected warns that the context is protected and asks
+// for confirmation when the context requires it
+func (o *ContextOptions) confirmProtected(name, ns string) error {
+	color := protectedColor(o.KubeWideConfig, name)
+	if color == nil {
+		return nil
+	}
+
+	if ns == "" {
+		ns = o.Config.Contexts[name].Namespace
+	}
+	fmt.Fprintln(o.ErrOut, color(fmt.Sprintf("!!! PROTECTED CONTEXT: %s (namespace: %s) !!!", name, ns)))
+
+	if !o.KubeWideConfig.Context(name).RequireConfirm || o.confirmed {
+		return nil
+	}
+
+	ok, err := common.Confirm(o.In, o.ErrOut, fmt.Sprintf("Switch to the protected context %s?", name))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("switch to the protected context %s cancelled", name)
+	}
+
+	return nil
+}
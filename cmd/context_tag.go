@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	tagExamples = templates.Examples(`
+		# List the tags of a context
+		kw ctx tag minikube
+
+		# Add or update tags of a context
+		kw ctx tag minikube env=dev team=payments
+
+		# Remove the tag team of a context
+		kw ctx tag minikube team-
+		`)
+)
+
+func newCmdContextTag(o *ContextOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tag NAME [KEY=VALUE ...] [KEY- ...]",
+		Short:   "Manage the tags of a context",
+		Args:    cobra.MinimumNArgs(1),
+		Example: tagExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := o.KubeWideConfig.ResolveAlias(args[0])
+			if _, ok := o.Config.Contexts[name]; !ok {
+				return fmt.Errorf("context not found: %s", name)
+			}
+
+			if len(args) == 1 {
+				o.printTags(name)
+				return nil
+			}
+
+			for _, t := range args[1:] {
+				if strings.HasSuffix(t, "-") {
+					o.KubeWideConfig.RemoveTag(name, strings.TrimSuffix(t, "-"))
+					continue
+				}
+
+				kv := strings.SplitN(t, "=", 2)
+				if len(kv) != 2 {
+					return fmt.Errorf("invalid tag, it must be KEY=VALUE or KEY-: %s", t)
+				}
+				if errs := validation.IsQualifiedName(kv[0]); len(errs) > 0 {
+					return fmt.Errorf("invalid tag key %q: %s", kv[0], strings.Join(errs, "; "))
+				}
+				if errs := validation.IsValidLabelValue(kv[1]); len(errs) > 0 {
+					return fmt.Errorf("invalid tag value %q: %s", kv[1], strings.Join(errs, "; "))
+				}
+				o.KubeWideConfig.SetTag(name, kv[0], kv[1])
+			}
+
+			return o.KubeWideConfig.Write()
+		},
+	}
+
+	return cmd
+}
+
+func (o *ContextOptions) printTags(name string) {
+	tags := o.KubeWideConfig.Context(name).Tags
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(o.Out, "%s=%s\n", k, tags[k])
+	}
+}
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"testing"
 
 	"github.com/leocomelli/kw/pkg/config"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 func TestParseContextArg(t *testing.T) {
@@ -17,10 +20,26 @@ func TestParseContextArg(t *testing.T) {
 	os.Setenv("KW_CONFIG", f.Name())
 
 	previousContext, _ := config.NewKubeWideConfig()
-	previousContext.SetPreviousContext("previousCtx")
+	previousContext.SetPreviousContext("previousCtx", "currentCtx")
 
 	previousNamespace, _ := config.NewKubeWideConfig()
-	previousNamespace.SetPreviousNamespace("previousNs")
+	previousNamespace.SetContextNamespace("gke_cluster", "previousNs", "currentNs")
+	previousNamespace.SetPreviousNamespace("otherClusterNs")
+
+	aliases, _ := config.NewKubeWideConfig()
+	aliases.SetAlias("prod", "gke_project-1234_europe-west1_prod-main")
+
+	history, _ := config.NewKubeWideConfig()
+	history.SetPreviousContext("firstCtx", "secondCtx")
+	history.SetPreviousContext("secondCtx", "thirdCtx")
+	history.SetContextNamespace("gke_cluster", "firstNs", "secondNs")
+	history.SetContextNamespace("gke_cluster", "secondNs", "thirdNs")
+	history.SetContextNamespace("minikube", "", "kube-system")
+
+	c := clientcmdapi.NewConfig()
+	c.Contexts["gke_cluster"] = &clientcmdapi.Context{}
+	c.Contexts["gke_project-1234_europe-west1_prod-main"] = &clientcmdapi.Context{}
+	c.Contexts["minikube"] = &clientcmdapi.Context{}
 
 	tests := []struct {
 		TestName  string
@@ -28,19 +47,120 @@ func TestParseContextArg(t *testing.T) {
 		Arg       string
 		Context   string
 		Namespace string
+		Err       string
 	}{
-		{"only context", &ContextOptions{}, "gke_cluster", "gke_cluster", ""},
-		{"context and namespace", &ContextOptions{}, "gke_cluster:kube-system", "gke_cluster", "kube-system"},
-		{"context and empty namespace", &ContextOptions{}, "gke_cluster:", "gke_cluster", ""},
-		{"previous context", &ContextOptions{KubeWideConfig: previousContext}, "-", "previousCtx", ""},
-		{"previous namespace", &ContextOptions{KubeWideConfig: previousNamespace}, "gke_cluster:-", "gke_cluster", "previousNs"},
+		{"only context", &ContextOptions{Config: c, KubeWideConfig: aliases}, "gke_cluster", "gke_cluster", "", ""},
+		{"context and namespace", &ContextOptions{Config: c, KubeWideConfig: aliases}, "gke_cluster:kube-system", "gke_cluster", "kube-system", ""},
+		{"context and empty namespace", &ContextOptions{Config: c, KubeWideConfig: aliases}, "gke_cluster:", "gke_cluster", "", ""},
+		{"previous context", &ContextOptions{Config: c, KubeWideConfig: previousContext}, "-", "previousCtx", "", ""},
+		{"previous namespace", &ContextOptions{Config: c, KubeWideConfig: previousNamespace}, "gke_cluster:-", "gke_cluster", "previousNs", ""},
+		{"history context", &ContextOptions{Config: c, KubeWideConfig: history}, "@2", "firstCtx", "", ""},
+		{"history namespace", &ContextOptions{Config: c, KubeWideConfig: history}, "gke_cluster:@1", "gke_cluster", "secondNs", ""},
+		{"history out of range", &ContextOptions{Config: c, KubeWideConfig: history}, "@3", "", "", "context not found in the history: @3"},
+		{"alias", &ContextOptions{Config: c, KubeWideConfig: aliases}, "prod", "gke_project-1234_europe-west1_prod-main", "", ""},
+		{"alias and namespace", &ContextOptions{Config: c, KubeWideConfig: aliases}, "prod:kube-system", "gke_project-1234_europe-west1_prod-main", "kube-system", ""},
+		{"no previous context", &ContextOptions{Config: c, KubeWideConfig: aliases}, "-", "", "", "no previous context"},
+		{"no previous namespace", &ContextOptions{Config: c, KubeWideConfig: aliases}, "prod:-", "", "", "no previous namespace in the context: gke_project-1234_europe-west1_prod-main"},
+		{"history namespace of another context", &ContextOptions{Config: c, KubeWideConfig: history}, "minikube:@1", "minikube", "default", ""},
+		{"substring and previous namespace", &ContextOptions{Config: c, KubeWideConfig: previousNamespace}, "cluster:-", "gke_cluster", "previousNs", ""},
+		{"glob and history namespace", &ContextOptions{Config: c, KubeWideConfig: history}, "*_cluster:@1", "gke_cluster", "secondNs", ""},
+		{"unknown context and namespace", &ContextOptions{Config: c, KubeWideConfig: history}, "staging:-", "", "", "context not found: staging"},
+		{"ambiguous context and namespace", &ContextOptions{Config: c, KubeWideConfig: history, IOStreams: genericclioptions.IOStreams{In: &bytes.Buffer{}}}, "gke:-", "", "", `context "gke" is ambiguous, it matches: gke_cluster, gke_project-1234_europe-west1_prod-main`},
+		{"history namespace out of range", &ContextOptions{Config: c, KubeWideConfig: history}, "gke_cluster:@3", "", "", "namespace not found in the history of the context gke_cluster: @3"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.TestName, func(t *testing.T) {
-			ctx, ns := tt.Opts.parseContextArg(tt.Arg)
+			ctx, ns, err := tt.Opts.parseContextArg(tt.Arg)
+			if tt.Err != "" {
+				assert.EqualError(t, err, tt.Err)
+			} else {
+				assert.NoError(t, err)
+			}
 			assert.Equal(t, tt.Context, ctx)
 			assert.Equal(t, tt.Namespace, ns)
 		})
 	}
 }
+
+func TestPreviewContext(t *testing.T) {
+
+	c := clientcmdapi.NewConfig()
+	c.Clusters["c1"] = &clientcmdapi.Cluster{Server: "https://c1"}
+	c.AuthInfos["u1"] = &clientcmdapi.AuthInfo{Token: "t"}
+	c.Contexts["gke_project-1234_europe-west1_prod-main"] = &clientcmdapi.Context{Cluster: "c1", AuthInfo: "u1"}
+
+	kw := &config.KubeWideConfig{}
+	kw.SetAlias("prod", "gke_project-1234_europe-west1_prod-main")
+
+	o := &ContextOptions{Config: c, KubeWideConfig: kw}
+
+	p := o.previewContext("prod")
+	assert.Contains(t, p, "Server:     https://c1\n")
+	assert.Contains(t, p, "Auth:       token\n")
+	assert.Contains(t, p, "Namespace:  default\n")
+	assert.Contains(t, p, "Last used:  never\n")
+	assert.Equal(t, "", o.previewContext("unknown"))
+}
+
+func TestMatchContexts(t *testing.T) {
+
+	names := []string{"gke_project_europe-west1_prod-eu", "gke_project_us-east1_prod-us", "minikube"}
+
+	tests := []struct {
+		TestName string
+		Query    string
+		Expected []string
+	}{
+		{"unique substring", "mini", []string{"minikube"}},
+		{"ambiguous substring", "prod", []string{"gke_project_europe-west1_prod-eu", "gke_project_us-east1_prod-us"}},
+		{"glob", "*prod-eu*", []string{"gke_project_europe-west1_prod-eu"}},
+		{"glob anchored", "prod-*", nil},
+		{"glob single char", "gke_project_*_prod-?s", []string{"gke_project_us-east1_prod-us"}},
+		{"no match", "staging", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, matchContexts(names, tt.Query))
+		})
+	}
+}
+
+func TestResolveContext(t *testing.T) {
+
+	c := clientcmdapi.NewConfig()
+	c.Contexts["minikube"] = &clientcmdapi.Context{}
+	c.Contexts["prod-eu"] = &clientcmdapi.Context{}
+
+	o := &ContextOptions{
+		Config:         c,
+		KubeWideConfig: &config.KubeWideConfig{},
+		IOStreams:      genericclioptions.IOStreams{In: &bytes.Buffer{}},
+	}
+
+	tests := []struct {
+		TestName string
+		Query    string
+		Expected string
+		Err      string
+	}{
+		{"exact name", "minikube", "minikube", ""},
+		{"unique substring", "eu", "prod-eu", ""},
+		{"empty query", "", "", "context name is required"},
+		{"no match", "staging", "", "context not found: staging"},
+		{"ambiguous without terminal", "u", "", `context "u" is ambiguous, it matches: minikube, prod-eu`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			ctx, err := o.resolveContext(tt.Query)
+			if tt.Err != "" {
+				assert.EqualError(t, err, tt.Err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.Expected, ctx)
+		})
+	}
+}
@@ -1,20 +1,230 @@
 package cmd
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leocomelli/kw/pkg/common"
+	"github.com/leocomelli/kw/pkg/config"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/kubernetes/pkg/kubectl/cmd"
 
 	// Import to initialize client auth plugins.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
 
+// mutatingVerbs contains the kubectl commands that change the cluster state,
+// the nested commands are separated by a space. The subcommands of a
+// mutating command, e.g. set image, are mutating as well.
+var mutatingVerbs = map[string]bool{
+	"annotate":            true,
+	"apply":               true,
+	"autoscale":           true,
+	"certificate approve": true,
+	"certificate deny":    true,
+	"cordon":              true,
+	"create":              true,
+	"delete":              true,
+	"drain":               true,
+	"edit":                true,
+	"expose":              true,
+	"label":               true,
+	"patch":               true,
+	"replace":             true,
+	"rollout pause":       true,
+	"rollout restart":     true,
+	"rollout resume":      true,
+	"rollout undo":        true,
+	"run":                 true,
+	"scale":               true,
+	"set":                 true,
+	"taint":               true,
+	"uncordon":            true,
+}
+
 // NewCmdKubectl creates a command object that wraps the kubectl oficial command
 func NewCmdKubectl(streams genericclioptions.IOStreams) *cobra.Command {
+	var yes bool
+	fanOut := &FanOutOptions{IOStreams: streams}
+
 	cmdKubectlWrap := cmd.NewKubectlCommand(streams.In, streams.Out, streams.ErrOut)
 	cmdKubectlWrap.Use = "ctl"
 	cmdKubectlWrap.Aliases = []string{"control", "kubectl"}
 	cmdKubectlWrap.Short = "Wraps the official kubectl command"
 
+	preRun := cmdKubectlWrap.PersistentPreRunE
+	cmdKubectlWrap.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		// the command runs again for each context, so this
+		// process only waits for them and reports the exit codes
+		if fanOut.enabled() {
+			// the fan-out errors are not related to the kubectl usage
+			c.SilenceUsage = true
+			code, err := fanOut.run(c, yes)
+			if err != nil {
+				return err
+			}
+
+			// the output of each context has already been written,
+			// the error only stops kubectl from running in this process
+			c.SilenceErrors = true
+			return &ExitError{Code: code}
+		}
+
+		if err := guardProtectedContext(c, streams, yes); err != nil {
+			c.SilenceUsage = true
+			return err
+		}
+		if preRun != nil {
+			return preRun(c, args)
+		}
+		return nil
+	}
+
+	fanOut.addFlags(cmdKubectlWrap)
+	cmdKubectlWrap.PersistentFlags().BoolVar(&yes, "yes", yes, "Do not ask for confirmation when a mutating command runs against a protected context.")
+
 	return cmdKubectlWrap
 }
+
+// guardProtectedContext requires the user to type the context name
+// before running a mutating command against a protected context
+func guardProtectedContext(c *cobra.Command, streams genericclioptions.IOStreams, yes bool) error {
+	verb := commandVerb(c)
+	if !isMutating(verb) {
+		return nil
+	}
+
+	kw, err := config.NewKubeWideConfig()
+	if err != nil {
+		return err
+	}
+
+	context, namespace, err := effectiveContext(c, kw)
+	if err != nil {
+		return err
+	}
+
+	color := protectedColor(kw, context)
+	if color == nil {
+		return nil
+	}
+	fmt.Fprintln(streams.ErrOut, color(fmt.Sprintf("!!! PROTECTED CONTEXT: %s (namespace: %s) - kubectl %s !!!", context, namespace, verb)))
+
+	if yes {
+		return nil
+	}
+
+	answer, err := common.Prompt(streams.In, streams.ErrOut, "Type the context name to continue: ")
+	if err != nil {
+		return err
+	}
+	if answer != context {
+		return fmt.Errorf("kubectl %s against the protected context %s cancelled", verb, context)
+	}
+
+	return nil
+}
+
+// commandVerb returns the kubectl command path without the root command, e.g. rollout restart
+func commandVerb(c *cobra.Command) string {
+	var names []string
+	for ; c.HasParent() && c.Name() != "ctl"; c = c.Parent() {
+		names = append([]string{c.Name()}, names...)
+	}
+
+	return strings.Join(names, " ")
+}
+
+// isMutating reports whether the kubectl command, or any of its parent
+// commands, changes the cluster state
+func isMutating(verb string) bool {
+	names := strings.Fields(verb)
+	for i := range names {
+		if mutatingVerbs[strings.Join(names[:i+1], " ")] {
+			return true
+		}
+	}
+	return false
+}
+
+// effectiveContext returns the context and namespace used by the kubectl
+// command. An alias given to --context is replaced by the context name, so
+// kubectl uses it as well. When --cluster or --user are given, a protected
+// context using the same cluster is returned, preferably with the same
+// user, since the command targets its cluster.
+func effectiveContext(c *cobra.Command, kw *config.KubeWideConfig) (string, string, error) {
+	flag := func(name string) string {
+		if f := c.Flags().Lookup(name); f != nil {
+			return f.Value.String()
+		}
+		return ""
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = flag("kubeconfig")
+
+	kc, err := rules.Load()
+	if err != nil {
+		return "", "", fmt.Errorf("error loading the kubeconfig: %w", err)
+	}
+
+	context := flag("context")
+	if context == "" {
+		context = kc.CurrentContext
+	} else if name := kw.ResolveAlias(context); name != context {
+		if err := c.Flags().Set("context", name); err != nil {
+			return "", "", err
+		}
+		context = name
+	}
+
+	if cluster, user := flag("cluster"), flag("user"); cluster != "" || user != "" {
+		if ctx, ok := kc.Contexts[context]; ok {
+			if cluster == "" {
+				cluster = ctx.Cluster
+			}
+			if user == "" {
+				user = ctx.AuthInfo
+			}
+		}
+
+		names := make([]string, 0, len(kc.Contexts))
+		for name := range kc.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var sameCluster string
+		for _, name := range names {
+			ctx := kc.Contexts[name]
+			if ctx.Cluster != cluster || !kw.Context(name).Protected {
+				continue
+			}
+			if ctx.AuthInfo == user {
+				sameCluster = name
+				break
+			}
+			if sameCluster == "" {
+				sameCluster = name
+			}
+		}
+		if sameCluster != "" {
+			context = sameCluster
+		}
+	}
+
+	namespace := flag("namespace")
+	if namespace == "" {
+		if ctx, ok := kc.Contexts[context]; ok {
+			namespace = ctx.Namespace
+		}
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	return context, namespace, nil
+}
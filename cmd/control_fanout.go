@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/leocomelli/kw/pkg/common"
+	"github.com/leocomelli/kw/pkg/config"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	defaultFanOutParallel = 4
+
+	// fanOutEnv marks the kw processes run for each context
+	fanOutEnv = "KW_FAN_OUT"
+)
+
+// fanOutFlags are removed from the arguments of the commands run for each context
+var fanOutFlags = []string{"contexts", "context-selector", "parallel"}
+
+// FanOutOptions contains the input to run a kubectl command against several contexts
+type FanOutOptions struct {
+	Contexts []string
+	Selector string
+	Parallel int
+
+	genericclioptions.IOStreams
+}
+
+// fanOutResult is the exit code of the command run for a context
+type fanOutResult struct {
+	Context string
+	Code    int
+	Err     error
+}
+
+func (o *FanOutOptions) enabled() bool {
+	return len(o.Contexts) > 0 || o.Selector != ""
+}
+
+// addFlags adds the fan-out flags to the kubectl wrapper
+func (o *FanOutOptions) addFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceVar(&o.Contexts, "contexts", o.Contexts, "Run the command against each of these contexts.")
+	cmd.PersistentFlags().StringVar(&o.Selector, "context-selector", o.Selector, "Run the command against each context whose tags match the selector, e.g. env=prod.")
+	cmd.PersistentFlags().IntVar(&o.Parallel, "parallel", defaultFanOutParallel, "Maximum number of contexts the command runs against at the same time.")
+}
+
+// run executes kw again for each context, passing the same arguments and
+// the context flag, and returns the exit code of the whole execution
+func (o *FanOutOptions) run(c *cobra.Command, yes bool) (int, error) {
+	if f := c.Flags().Lookup("context"); f != nil && f.Changed {
+		return 0, fmt.Errorf("--context cannot be used with --contexts or --context-selector")
+	}
+	if o.Parallel < 1 {
+		return 0, fmt.Errorf("--parallel must be greater than zero")
+	}
+
+	contexts, err := o.targetContexts(c)
+	if err != nil {
+		return 0, err
+	}
+	if len(contexts) == 0 {
+		return 0, fmt.Errorf("no context matches the selector: %s", o.Selector)
+	}
+
+	verb := commandVerb(c)
+	if err := o.checkProtected(contexts, verb, yes); err != nil {
+		return 0, err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("error finding the kw executable: %w", err)
+	}
+	args := stripFlags(os.Args[1:], fanOutFlags...)
+
+	width := 0
+	for _, ctx := range contexts {
+		if len(ctx) > width {
+			width = len(ctx)
+		}
+	}
+
+	pc := common.NewPrintColor()
+	colors := make([]common.PrintFn, len(contexts))
+	for i := range contexts {
+		colors[i] = pc.Get()
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, o.Parallel)
+		results = make([]fanOutResult, len(contexts))
+	)
+	for i, ctx := range contexts {
+		wg.Add(1)
+		go func(i int, ctx string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			prefix := colors[i](fmt.Sprintf("%-*s", width, ctx)) + " | "
+			code, err := runPrefixed(exe, withContext(args, ctx), prefix, o.Out, o.ErrOut, &mu)
+			results[i] = fanOutResult{Context: ctx, Code: code, Err: err}
+		}(i, ctx)
+	}
+	wg.Wait()
+
+	return o.printSummary(results), nil
+}
+
+// targetContexts returns the contexts given by name or matching the selector
+func (o *FanOutOptions) targetContexts(c *cobra.Command) ([]string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if f := c.Flags().Lookup("kubeconfig"); f != nil {
+		rules.ExplicitPath = f.Value.String()
+	}
+
+	kc, err := rules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("error loading the kubeconfig: %w", err)
+	}
+
+	kw, err := config.NewKubeWideConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []string
+	seen := make(map[string]bool)
+	for _, name := range o.Contexts {
+		name = kw.ResolveAlias(name)
+		if _, ok := kc.Contexts[name]; !ok {
+			return nil, fmt.Errorf("context not found: %s", name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			contexts = append(contexts, name)
+		}
+	}
+
+	if o.Selector != "" {
+		selector, err := labels.Parse(o.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector: %w", err)
+		}
+
+		var matched []string
+		for name := range kc.Contexts {
+			if selector.Matches(labels.Set(kw.Context(name).Tags)) && !seen[name] {
+				matched = append(matched, name)
+			}
+		}
+		sort.Strings(matched)
+		contexts = append(contexts, matched...)
+	}
+
+	return contexts, nil
+}
+
+// checkProtected refuses to run a mutating command against protected
+// contexts without --yes, the confirmation cannot be asked for each
+// context because the commands run at the same time
+func (o *FanOutOptions) checkProtected(contexts []string, verb string, yes bool) error {
+	if !isMutating(verb) || yes {
+		return nil
+	}
+
+	kw, err := config.NewKubeWideConfig()
+	if err != nil {
+		return err
+	}
+
+	var protected []string
+	for _, ctx := range contexts {
+		if kw.Context(ctx).Protected {
+			protected = append(protected, ctx)
+		}
+	}
+	if len(protected) > 0 {
+		return fmt.Errorf("kubectl %s against the protected contexts %s requires --yes", verb, strings.Join(protected, ", "))
+	}
+
+	return nil
+}
+
+// printSummary writes the exit code of each context and returns
+// a non-zero code when the command failed for any context
+func (o *FanOutOptions) printSummary(results []fanOutResult) int {
+	red, _ := common.NewPrintColor().GetByName("red")
+
+	var (
+		code   int
+		data   [][]string
+		colors []common.PrintFn
+	)
+	for _, r := range results {
+		status := strconv.Itoa(r.Code)
+		if r.Err != nil {
+			status = r.Err.Error()
+		}
+
+		if r.Code != 0 || r.Err != nil {
+			code = 1
+			colors = append(colors, red)
+		} else {
+			colors = append(colors, nil)
+		}
+		data = append(data, []string{r.Context, status})
+	}
+
+	fmt.Fprintln(o.ErrOut)
+	common.TabPrintFn(o.ErrOut, []string{"CONTEXT", "EXIT CODE"}, data, colors)
+
+	return code
+}
+
+// runPrefixed runs the command writing each line of its output with the
+// prefix, the mutex avoids mixing the lines of concurrent commands
+func runPrefixed(name string, args []string, prefix string, out, errOut io.Writer, mu *sync.Mutex) (int, error) {
+	c := exec.Command(name, args...)
+	c.Env = append(os.Environ(), fanOutEnv+"=1")
+
+	stdout, err := c.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+	stderr, err := c.StderrPipe()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.Start(); err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	copyLines := func(r io.Reader, w io.Writer) {
+		defer wg.Done()
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			mu.Lock()
+			fmt.Fprintln(w, prefix+scanner.Text())
+			mu.Unlock()
+		}
+	}
+	wg.Add(2)
+	go copyLines(stdout, out)
+	go copyLines(stderr, errOut)
+	wg.Wait()
+
+	if err := c.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), nil
+		}
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+// stripFlags removes the flags and their values from the arguments,
+// the arguments after -- are kept as they are
+func stripFlags(args []string, names ...string) []string {
+	var stripped []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			return append(stripped, args[i:]...)
+		}
+
+		removed := false
+		for _, n := range names {
+			if a == "--"+n {
+				// the value is the next argument
+				i++
+				removed = true
+				break
+			}
+			if strings.HasPrefix(a, "--"+n+"=") {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			stripped = append(stripped, a)
+		}
+	}
+
+	return stripped
+}
+
+// withContext adds the context flag before the arguments given after --
+func withContext(args []string, context string) []string {
+	flag := "--context=" + context
+
+	withCtx := make([]string, 0, len(args)+1)
+	for i, a := range args {
+		if a == "--" {
+			withCtx = append(withCtx, flag)
+			return append(withCtx, args[i:]...)
+		}
+		withCtx = append(withCtx, a)
+	}
+
+	return append(withCtx, flag)
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leocomelli/kw/pkg/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestCommandVerb(t *testing.T) {
+
+	root := &cobra.Command{Use: "kw"}
+	ctl := &cobra.Command{Use: "ctl"}
+	rollout := &cobra.Command{Use: "rollout"}
+	restart := &cobra.Command{Use: "restart"}
+	status := &cobra.Command{Use: "status"}
+	get := &cobra.Command{Use: "get"}
+	set := &cobra.Command{Use: "set"}
+	image := &cobra.Command{Use: "image"}
+	create := &cobra.Command{Use: "create"}
+	deployment := &cobra.Command{Use: "deployment"}
+	secret := &cobra.Command{Use: "secret"}
+	generic := &cobra.Command{Use: "generic"}
+
+	root.AddCommand(ctl)
+	ctl.AddCommand(rollout, get, set, create)
+	rollout.AddCommand(restart, status)
+	set.AddCommand(image)
+	create.AddCommand(deployment, secret)
+	secret.AddCommand(generic)
+
+	tests := []struct {
+		TestName string
+		Cmd      *cobra.Command
+		Verb     string
+		Mutating bool
+	}{
+		{"wrapper", ctl, "", false},
+		{"read only", get, "get", false},
+		{"nested", restart, "rollout restart", true},
+		{"parent of nested", rollout, "rollout", false},
+		{"read only nested", status, "rollout status", false},
+		{"set image", image, "set image", true},
+		{"create deployment", deployment, "create deployment", true},
+		{"create secret generic", generic, "create secret generic", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			verb := commandVerb(tt.Cmd)
+			assert.Equal(t, tt.Verb, verb)
+			assert.Equal(t, tt.Mutating, isMutating(verb))
+		})
+	}
+}
+
+func TestEffectiveContext(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config")
+	kc := clientcmdapi.NewConfig()
+	kc.Contexts["staging"] = &clientcmdapi.Context{Cluster: "staging", AuthInfo: "staging"}
+	kc.Contexts["prod"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "admin", Namespace: "payments"}
+	kc.CurrentContext = "staging"
+	assert.NoError(t, clientcmd.WriteToFile(*kc, file))
+
+	kw := &config.KubeWideConfig{}
+	kw.SetAlias("p", "prod")
+	kw.Protect("prod", "red", true)
+
+	tests := []struct {
+		TestName  string
+		Args      []string
+		Context   string
+		Namespace string
+	}{
+		{"current context", nil, "staging", "default"},
+		{"context flag", []string{"--context", "prod"}, "prod", "payments"},
+		{"alias", []string{"--context", "p"}, "prod", "payments"},
+		{"cluster and user of a protected context", []string{"--cluster", "prod", "--user", "admin"}, "prod", "payments"},
+		{"cluster only", []string{"--cluster", "prod"}, "prod", "payments"},
+		{"cluster of a protected context and another user", []string{"--cluster", "prod", "--user", "staging"}, "prod", "payments"},
+		{"user only", []string{"--user", "admin"}, "staging", "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			c := &cobra.Command{Use: "delete"}
+			for _, f := range []string{"kubeconfig", "context", "cluster", "user", "namespace"} {
+				c.Flags().String(f, "", "")
+			}
+			assert.NoError(t, c.Flags().Parse(append([]string{"--kubeconfig", file}, tt.Args...)))
+
+			context, namespace, err := effectiveContext(c, kw)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Context, context)
+			assert.Equal(t, tt.Namespace, namespace)
+			if tt.Args != nil && tt.Args[0] == "--context" {
+				assert.Equal(t, "prod", c.Flags().Lookup("context").Value.String())
+			}
+		})
+	}
+}
+
+func TestFanOutArgs(t *testing.T) {
+
+	tests := []struct {
+		TestName string
+		Args     []string
+		Expected []string
+	}{
+		{"separate values", []string{"ctl", "get", "pods", "--contexts", "a,b", "--parallel", "2"}, []string{"ctl", "get", "pods", "--context=a"}},
+		{"inline values", []string{"ctl", "--context-selector=env=prod", "get", "ns"}, []string{"ctl", "get", "ns", "--context=a"}},
+		{"after dash", []string{"ctl", "exec", "pod", "--contexts=a", "--", "ls", "--parallel", "1"}, []string{"ctl", "exec", "pod", "--context=a", "--", "ls", "--parallel", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			args := stripFlags(tt.Args, fanOutFlags...)
+			assert.Equal(t, tt.Expected, withContext(args, "a"))
+		})
+	}
+}
+
+// newFanOutTestConfig writes a kubeconfig and a kw config whose
+// prod contexts are tagged, prod-eu is protected and aliased as eu
+func newFanOutTestConfig(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(t, err)
+
+	file := filepath.Join(dir, "config")
+	kc := clientcmdapi.NewConfig()
+	for _, name := range []string{"staging", "prod-eu", "prod-us"} {
+		kc.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+	}
+	assert.NoError(t, clientcmd.WriteToFile(*kc, file))
+
+	os.Setenv("KW_CONFIG", filepath.Join(dir, "kw.yml"))
+	kw, err := config.NewKubeWideConfig()
+	assert.NoError(t, err)
+	kw.SetAlias("eu", "prod-eu")
+	kw.SetTag("prod-eu", "env", "prod")
+	kw.SetTag("prod-us", "env", "prod")
+	kw.Protect("prod-eu", "red", false)
+	assert.NoError(t, kw.Write())
+
+	return file, func() {
+		os.Unsetenv("KW_CONFIG")
+		os.RemoveAll(dir)
+	}
+}
+
+func TestTargetContexts(t *testing.T) {
+
+	file, cleanup := newFanOutTestConfig(t)
+	defer cleanup()
+
+	tests := []struct {
+		TestName string
+		Contexts []string
+		Selector string
+		Expected []string
+		Err      string
+	}{
+		{"names", []string{"staging", "prod-us"}, "", []string{"staging", "prod-us"}, ""},
+		{"alias", []string{"eu"}, "", []string{"prod-eu"}, ""},
+		{"duplicated through an alias", []string{"prod-eu", "eu"}, "", []string{"prod-eu"}, ""},
+		{"selector", nil, "env=prod", []string{"prod-eu", "prod-us"}, ""},
+		{"names and selector", []string{"prod-us", "staging"}, "env=prod", []string{"prod-us", "staging", "prod-eu"}, ""},
+		{"no match", nil, "env=dev", nil, ""},
+		{"unknown context", []string{"dev"}, "", nil, "context not found: dev"},
+		{"invalid selector", nil, "env in (", nil, "invalid selector"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			c := &cobra.Command{Use: "get"}
+			c.Flags().String("kubeconfig", file, "")
+
+			o := &FanOutOptions{Contexts: tt.Contexts, Selector: tt.Selector}
+			contexts, err := o.targetContexts(c)
+			if tt.Err != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.Err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.Expected, contexts)
+		})
+	}
+}
+
+func TestFanOutCheckProtected(t *testing.T) {
+
+	_, cleanup := newFanOutTestConfig(t)
+	defer cleanup()
+
+	tests := []struct {
+		TestName string
+		Contexts []string
+		Verb     string
+		Yes      bool
+		Err      string
+	}{
+		{"read only", []string{"prod-eu", "prod-us"}, "get", false, ""},
+		{"mutating without protected context", []string{"staging", "prod-us"}, "delete", false, ""},
+		{"mutating with protected context", []string{"prod-eu", "prod-us"}, "delete", false, "kubectl delete against the protected contexts prod-eu requires --yes"},
+		{"nested mutating with protected context", []string{"prod-eu"}, "set image", false, "kubectl set image against the protected contexts prod-eu requires --yes"},
+		{"mutating with yes", []string{"prod-eu", "prod-us"}, "rollout restart", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			o := &FanOutOptions{}
+			err := o.checkProtected(tt.Contexts, tt.Verb, tt.Yes)
+			if tt.Err != "" {
+				assert.EqualError(t, err, tt.Err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFanOutPrintSummary(t *testing.T) {
+
+	tests := []struct {
+		TestName string
+		Results  []fanOutResult
+		Code     int
+		Output   string
+	}{
+		{"all succeeded", []fanOutResult{{"prod-eu", 0, nil}, {"prod-us", 0, nil}}, 0, "prod-us\t0"},
+		{"non-zero exit code", []fanOutResult{{"prod-eu", 0, nil}, {"prod-us", 2, nil}}, 1, "prod-us\t2"},
+		{"command not started", []fanOutResult{{"prod-eu", 0, fmt.Errorf("no such file")}}, 1, "prod-eu\tno such file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			errOut := &bytes.Buffer{}
+			o := &FanOutOptions{IOStreams: genericclioptions.IOStreams{ErrOut: errOut}}
+
+			assert.Equal(t, tt.Code, o.printSummary(tt.Results))
+			assert.Contains(t, errOut.String(), tt.Output)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	execInExamples = templates.Examples(`
+		# List the helm releases of a context without modifying the current context
+		kw exec-in prod -- helm list
+
+		# Run kubectl against a context and namespace
+		kw exec-in minikube:kube-system -- kubectl get pods
+
+		# Run terraform against the previous context
+		kw exec-in - -- terraform plan
+		`)
+)
+
+// NewCmdExecIn creates a command object that runs a command against a
+// context using a temporary kubeconfig
+func NewCmdExecIn(streams genericclioptions.IOStreams, configAccess *clientcmd.PathOptions) *cobra.Command {
+	o := newContextOptions(streams, configAccess)
+
+	cmd := &cobra.Command{
+		Use:                   "exec-in context[:namespace] -- COMMAND [ARGS...]",
+		Short:                 "Run a command against a context and namespace without modifying the current context",
+		Args:                  cobra.MinimumNArgs(2),
+		Example:               execInExamples,
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     o.completeContextArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.ArgsLenAtDash() != 1 {
+				return fmt.Errorf("the command must be given after --, e.g. kw exec-in %s -- kubectl get pods", args[0])
+			}
+
+			o.complete()
+
+			code, err := o.execIn(args[0], args[1], args[2:]...)
+			if err != nil {
+				return err
+			}
+			if code != 0 {
+				// the command has already reported its failure
+				cmd.SilenceUsage = true
+				cmd.SilenceErrors = true
+				return &ExitError{Code: code}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// execIn runs the command using a session kubeconfig whose current context
+// is the given one, the session file is removed when the command exits
+func (o *ContextOptions) execIn(arg, name string, args ...string) (int, error) {
+	ctx, ns, err := o.parseContextArg(arg)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, err = o.resolveContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := o.confirmProtected(ctx, ns); err != nil {
+		return 0, err
+	}
+
+	c := o.Config.DeepCopy()
+	if ns != "" {
+		c.Contexts[ctx].Namespace = ns
+	}
+
+	s, err := kubeconfig.NewSession(sessionFiles(o.PahtOptions, o.session), os.Getpid())
+	if err != nil {
+		return 0, err
+	}
+	defer s.Remove()
+
+	if err := s.Apply(c, ctx); err != nil {
+		return 0, err
+	}
+
+	return runInSession(o.IOStreams, s, name, args...)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestExecInExitCode(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the session files are created in the home directory
+	homedir.DisableCache = true
+	defer func() { homedir.DisableCache = false }()
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", dir)
+	os.Setenv("KW_CONFIG", filepath.Join(dir, "kw.yml"))
+	defer os.Unsetenv("KW_CONFIG")
+
+	c := clientcmdapi.NewConfig()
+	c.Clusters["minikube"] = &clientcmdapi.Cluster{Server: "https://minikube"}
+	c.AuthInfos["minikube"] = &clientcmdapi.AuthInfo{Token: "t"}
+	c.Contexts["minikube"] = &clientcmdapi.Context{Cluster: "minikube", AuthInfo: "minikube"}
+	kubeconfigPath := filepath.Join(dir, "config")
+	assert.NoError(t, clientcmd.WriteToFile(*c, kubeconfigPath))
+
+	po := clientcmd.NewDefaultPathOptions()
+	po.LoadingRules.ExplicitPath = kubeconfigPath
+
+	out := &bytes.Buffer{}
+	streams := genericclioptions.IOStreams{In: &bytes.Buffer{}, Out: out, ErrOut: out}
+
+	cmd := NewCmdExecIn(streams, po)
+	cmd.SetArgs([]string{"minikube", "--", "sh", "-c", "echo failed; exit 3"})
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+
+	err = cmd.Execute()
+	assert.Equal(t, &ExitError{Code: 3}, err)
+	assert.Equal(t, "failed\n", out.String())
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/leocomelli/kw/pkg/config"
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// sessionPath returns the path used to record the time-limited switch
+// of the session, the switches outside a session use an empty path
+func sessionPath(s *kubeconfig.Session) string {
+	if s == nil {
+		return ""
+	}
+	return s.Path
+}
+
+// setExpiry records when the switch to the context expires, a switch
+// without a duration makes the context permanent. Chained time-limited
+// switches revert to the context used before the first one.
+func (o *ContextOptions) setExpiry(ctx string, now time.Time) {
+	session := sessionPath(o.session)
+	if o.For == 0 {
+		o.KubeWideConfig.RemoveExpiry(session)
+		return
+	}
+
+	revert := o.Config.CurrentContext
+	if e := o.KubeWideConfig.Expiry(session); e != nil && !e.Expired(now) {
+		revert = e.Revert
+	}
+
+	// there is nothing to switch back to
+	if revert == ctx {
+		o.KubeWideConfig.RemoveExpiry(session)
+		return
+	}
+
+	o.KubeWideConfig.SetExpiry(config.Expiry{
+		Session:  session,
+		Context:  ctx,
+		Revert:   revert,
+		Deadline: now.Add(o.For),
+	})
+}
+
+// RevertExpired switches back to the previous context when the
+// time-limited switch of the current shell has expired. It runs before
+// the command, so no command acts on an expired context. The completion
+// and the prompt are run by the shell, and the commands run for each
+// context by the fan-out by a kw process that has already reverted the
+// switch, so it does not run for them. The errors are only reported.
+func RevertExpired(root *cobra.Command, args []string, in io.Reader, out, errOut io.Writer) {
+	if len(args) > 0 && strings.HasPrefix(args[0], cobra.ShellCompRequestCmd) {
+		return
+	}
+	if os.Getenv(fanOutEnv) != "" {
+		return
+	}
+	if c, _, err := root.Find(args); err == nil && c.Name() == "prompt" {
+		return
+	}
+
+	configAccess := clientcmd.NewDefaultPathOptions()
+	configAccess.LoadingRules.ExplicitPath = kubeconfigFlag(args)
+
+	streams := genericclioptions.IOStreams{In: in, Out: out, ErrOut: errOut}
+	if err := revertExpired(streams, configAccess, time.Now()); err != nil {
+		fmt.Fprintf(errOut, "warning: %v\n", err)
+	}
+}
+
+// kubeconfigFlag returns the value of the --kubeconfig flag, the
+// arguments are parsed by cobra only when the command runs
+func kubeconfigFlag(args []string) string {
+	var path string
+
+	fs := pflag.NewFlagSet("kw", pflag.ContinueOnError)
+	fs.ParseErrorsWhitelist.UnknownFlags = true
+	fs.SetOutput(ioutil.Discard)
+	fs.StringVar(&path, "kubeconfig", "", "")
+	_ = fs.Parse(args)
+
+	return path
+}
+
+// revertExpired switches back to the previous context like kw ctx does,
+// so the hooks run and the history is updated
+func revertExpired(streams genericclioptions.IOStreams, configAccess *clientcmd.PathOptions, now time.Time) error {
+	o := newContextOptions(streams, configAccess)
+	if err := o.load(); err != nil {
+		return err
+	}
+
+	kw := o.KubeWideConfig
+	e := kw.Expiry(sessionPath(o.session))
+	if e == nil || !e.Expired(now) {
+		return nil
+	}
+	expired := *e
+
+	// the switches of the sessions whose shell has exited will never expire
+	for _, x := range kw.Expiries {
+		if x.Session == "" {
+			continue
+		}
+		if _, err := os.Stat(x.Session); os.IsNotExist(err) {
+			kw.RemoveExpiry(x.Session)
+		}
+	}
+
+	// the context has been switched again without kw, so there is
+	// nothing to revert
+	if o.Config.CurrentContext != expired.Context {
+		kw.RemoveExpiry(expired.Session)
+		return kw.Write()
+	}
+
+	// the context used before the switch has been removed without kw
+	if _, ok := o.Config.Contexts[expired.Revert]; !ok && expired.Revert != "" {
+		kw.RemoveExpiry(expired.Session)
+		if err := kw.Write(); err != nil {
+			return err
+		}
+		return fmt.Errorf("error reverting the context %s: context not found: %s", expired.Context, expired.Revert)
+	}
+
+	// the user was already using the context before the switch
+	o.confirmed = true
+
+	var err error
+	if expired.Revert == "" {
+		o.Config.CurrentContext = ""
+		kw.RemoveExpiry(expired.Session)
+		err = o.write()
+	} else {
+		err = o.set(expired.Revert, "")
+	}
+	if err != nil {
+		return fmt.Errorf("error reverting the context %s: %w", expired.Context, err)
+	}
+
+	fmt.Fprintf(streams.ErrOut, "The switch to the context %s has expired, switched back to %s\n", expired.Context, displayContext(expired.Revert))
+
+	return nil
+}
+
+func displayContext(name string) string {
+	if name == "" {
+		return "no context"
+	}
+	return name
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leocomelli/kw/pkg/config"
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestRevertExpired(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("KW_CONFIG", filepath.Join(dir, "kw.yml"))
+	defer os.Unsetenv("KW_CONFIG")
+	os.Unsetenv(kubeconfig.SessionEnv)
+
+	file := filepath.Join(dir, "config")
+	c := clientcmdapi.NewConfig()
+	for _, name := range []string{"staging", "prod"} {
+		c.Clusters[name] = &clientcmdapi.Cluster{Server: "https://" + name}
+		c.AuthInfos[name] = &clientcmdapi.AuthInfo{Token: name}
+		c.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+	}
+	c.CurrentContext = "prod"
+	assert.NoError(t, clientcmd.WriteToFile(*c, file))
+
+	po := clientcmd.NewDefaultPathOptions()
+	po.LoadingRules.ExplicitPath = file
+
+	now := time.Now()
+	kw, err := config.NewKubeWideConfig()
+	assert.NoError(t, err)
+	kw.SetExpiry(config.Expiry{Context: "prod", Revert: "staging", Deadline: now.Add(time.Minute)})
+	kw.SetExpiry(config.Expiry{Session: filepath.Join(dir, "exited"), Context: "prod", Deadline: now})
+	// the revert does not ask for confirmation, the context was used before the switch
+	kw.Protect("staging", "red", true)
+	assert.NoError(t, kw.Write())
+
+	out := &bytes.Buffer{}
+	streams := genericclioptions.IOStreams{In: &bytes.Buffer{}, Out: out, ErrOut: out}
+
+	tests := []struct {
+		TestName string
+		Now      time.Time
+		Expected string
+		Expiries int
+	}{
+		{"before the deadline", now, "prod", 2},
+		{"after the deadline", now.Add(time.Minute), "staging", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			assert.NoError(t, revertExpired(streams, po, tt.Now))
+
+			c, err := clientcmd.LoadFromFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Expected, c.CurrentContext)
+
+			kw, err := config.NewKubeWideConfig()
+			assert.NoError(t, err)
+			assert.Len(t, kw.Expiries, tt.Expiries)
+		})
+	}
+	assert.Contains(t, out.String(), "switched back to staging")
+
+	// the revert is recorded like any other switch
+	kw, err = config.NewKubeWideConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", kw.PreviousContext())
+	assert.Equal(t, []string{"prod"}, kw.History.Contexts)
+}
+
+func TestRevertExpiredSkipped(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("KW_CONFIG", filepath.Join(dir, "kw.yml"))
+	defer os.Unsetenv("KW_CONFIG")
+	os.Unsetenv(kubeconfig.SessionEnv)
+
+	file := filepath.Join(dir, "config")
+
+	root := &cobra.Command{Use: "kw"}
+	root.PersistentFlags().String("kubeconfig", "", "")
+	root.AddCommand(&cobra.Command{Use: "ctx"}, &cobra.Command{Use: "prompt"})
+
+	tests := []struct {
+		TestName string
+		Args     []string
+		FanOut   bool
+		Expected string
+	}{
+		{"command", []string{"ctx", "--kubeconfig", file}, false, "staging"},
+		{"kubeconfig flag first", []string{"--kubeconfig=" + file, "ctx"}, false, "staging"},
+		{"completion", []string{"__complete", "--kubeconfig", file, "ctx", ""}, false, "prod"},
+		{"completion without descriptions", []string{"__completeNoDesc", "--kubeconfig", file, "ctx", ""}, false, "prod"},
+		{"prompt", []string{"--kubeconfig", file, "prompt"}, false, "prod"},
+		{"fan-out command", []string{"ctx", "--kubeconfig", file}, true, "prod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			c := clientcmdapi.NewConfig()
+			for _, name := range []string{"staging", "prod"} {
+				c.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+			}
+			c.CurrentContext = "prod"
+			assert.NoError(t, clientcmd.WriteToFile(*c, file))
+
+			kw, err := config.NewKubeWideConfig()
+			assert.NoError(t, err)
+			kw.SetExpiry(config.Expiry{Context: "prod", Revert: "staging", Deadline: time.Now().Add(-time.Minute)})
+			assert.NoError(t, kw.Write())
+
+			if tt.FanOut {
+				os.Setenv(fanOutEnv, "1")
+				defer os.Unsetenv(fanOutEnv)
+			}
+
+			out := &bytes.Buffer{}
+			RevertExpired(root, tt.Args, &bytes.Buffer{}, out, out)
+
+			c, err = clientcmd.LoadFromFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Expected, c.CurrentContext)
+			if tt.Expected == "prod" {
+				assert.Empty(t, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/leocomelli/kw/pkg/common"
+)
+
+// parseHistoryArg returns the number of steps referenced by an
+// argument such as @3, the second value reports whether the argument
+// uses the history syntax
+func parseHistoryArg(arg string) (int, bool) {
+	if !strings.HasPrefix(arg, HistoryIdentifier) {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(strings.TrimPrefix(arg, HistoryIdentifier))
+	if err != nil || n < 1 {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// printHistory writes the history entries using the tabular format
+func printHistory(w io.Writer, title string, entries []string, noHeaders bool) {
+	var headers []string
+	if !noHeaders {
+		headers = []string{"STEP", title}
+	}
+
+	var data [][]string
+	for i, e := range entries {
+		data = append(data, []string{fmt.Sprintf("%s%d", HistoryIdentifier, i+1), e})
+	}
+
+	common.TabPrint(w, headers, data)
+}
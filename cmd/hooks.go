@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/leocomelli/kw/pkg/config"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+const defaultHookTimeout = 30 * time.Second
+
+// Hook stages
+const (
+	hookPre  = "pre"
+	hookPost = "post"
+)
+
+// runHooks runs the pre or post commands of the hooks, it stops at the first failure
+func runHooks(streams genericclioptions.IOStreams, hooks []config.Hook, stage, ctx, ns string) error {
+	for _, h := range hooks {
+		script := h.Pre
+		if stage == hookPost {
+			script = h.Post
+		}
+		if script == "" {
+			continue
+		}
+
+		timeout := h.Timeout
+		if timeout == 0 {
+			timeout = defaultHookTimeout
+		}
+
+		if err := runHook(streams, script, stage, ctx, ns, timeout); err != nil {
+			return fmt.Errorf("%s hook %q of the context %s failed: %w", stage, script, ctx, err)
+		}
+	}
+
+	return nil
+}
+
+// runHook runs the script using sh, the target context and namespace are
+// exposed as environment variables. The output is written to the error
+// stream, so it does not mix with the output of kw ctx --session. The
+// script runs in its own process group, which is killed on timeout.
+func runHook(streams genericclioptions.IOStreams, script, stage, ctx, ns string, timeout time.Duration) error {
+	c := exec.Command("sh", "-c", script)
+	c.Stdin = streams.In
+	c.Stdout = streams.ErrOut
+	c.Stderr = streams.ErrOut
+	c.Env = append(os.Environ(), "KW_HOOK="+stage, "KW_CONTEXT="+ctx, "KW_NAMESPACE="+ns)
+	setProcessGroup(c)
+
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Wait()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		// killing only sh would leave its children holding the output
+		_ = killProcessGroup(c)
+		<-done
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
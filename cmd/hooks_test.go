@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/leocomelli/kw/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestRunHooks(t *testing.T) {
+
+	out := &bytes.Buffer{}
+	streams := genericclioptions.IOStreams{In: &bytes.Buffer{}, Out: &bytes.Buffer{}, ErrOut: out}
+
+	hooks := []config.Hook{
+		{Pre: `echo "$KW_HOOK $KW_CONTEXT $KW_NAMESPACE"`},
+		{Post: "exit 1"},
+	}
+
+	assert.NoError(t, runHooks(streams, hooks, hookPre, "minikube", "kube-system"))
+	assert.Equal(t, "pre minikube kube-system\n", out.String())
+
+	err := runHooks(streams, hooks, hookPost, "minikube", "kube-system")
+	assert.EqualError(t, err, `post hook "exit 1" of the context minikube failed: exit status 1`)
+
+	err = runHooks(streams, []config.Hook{{Pre: "exec sleep 5", Timeout: 100 * time.Millisecond}}, hookPre, "minikube", "default")
+	assert.EqualError(t, err, `pre hook "exec sleep 5" of the context minikube failed: timed out after 100ms`)
+
+	// the children of the shell are killed as well
+	start := time.Now()
+	err = runHooks(streams, []config.Hook{{Pre: "sleep 5; true", Timeout: 100 * time.Millisecond}}, hookPre, "minikube", "default")
+	assert.EqualError(t, err, `pre hook "sleep 5; true" of the context minikube failed: timed out after 100ms`)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
@@ -0,0 +1,13 @@
+package cmd
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup does nothing, process groups are not supported
+func setProcessGroup(c *exec.Cmd) {}
+
+// killProcessGroup kills the command
+func killProcessGroup(c *exec.Cmd) error {
+	return c.Process.Kill()
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/leocomelli/kw/pkg/kubernetes"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/kubectl/pkg/util/templates"
 )
 
@@ -34,21 +35,22 @@ type LogOptions struct {
 }
 
 // NewCmdLogs creates a command object for the logs actions
-func NewCmdLogs(streams genericclioptions.IOStreams) *cobra.Command {
+func NewCmdLogs(streams genericclioptions.IOStreams, configAccess *clientcmd.PathOptions) *cobra.Command {
 	o := &LogOptions{IOStreams: streams}
 
 	cmd := &cobra.Command{
-		Use:     "logs",
-		Aliases: []string{"l", "log"},
-		Short:   "Streams logs from all containers of all matched pods",
-		Args:    cobra.MaximumNArgs(1),
-		Example: logExamples,
+		Use:               "logs",
+		Aliases:           []string{"l", "log"},
+		Short:             "Streams logs from all containers of all matched pods",
+		Args:              cobra.MaximumNArgs(1),
+		Example:           logExamples,
+		ValidArgsFunction: cobra.NoFileCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if o.Namespace == "" {
 				return fmt.Errorf("namespace is required")
 			}
 
-			k, err := kubernetes.NewKubernetes()
+			k, err := kubernetes.NewKubernetes(configAccess.LoadingRules)
 			if err != nil {
 				return err
 			}
@@ -95,5 +97,7 @@ func NewCmdLogs(streams genericclioptions.IOStreams) *cobra.Command {
 	cmd.Flags().StringVarP(&o.Pod, "pod", "p", o.Pod, "match pods by name")
 	cmd.Flags().StringVarP(&o.Container, "container", "c", o.Container, "restrict which containers logs are shown for")
 
+	o.completeLogsFlags(cmd, configAccess)
+
 	return cmd
 }
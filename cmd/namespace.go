@@ -7,6 +7,7 @@ import (
 
 	"github.com/leocomelli/kw/pkg/common"
 	"github.com/leocomelli/kw/pkg/config"
+	"github.com/leocomelli/kw/pkg/kubeconfig"
 	"github.com/leocomelli/kw/pkg/kubernetes"
 	"github.com/spf13/cobra"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,8 +29,14 @@ var (
 		# Modify the current namespace
 		kw ns cert-manager
 
-		# Switch to the previous namespace
+		# Switch to the previous namespace of the current context
 		kw ns -
+
+		# List the namespaces recently used in the current context
+		kw ns --history
+
+		# Switch to the namespace used two steps ago
+		kw ns @2
 		`)
 )
 
@@ -37,55 +44,79 @@ var (
 type NamespaceOptions struct {
 	NoHeaders      bool
 	Interactive    bool
+	History        bool
 	Config         *clientcmdapi.Config
 	PahtOptions    *clientcmd.PathOptions
 	KubeWideConfig *config.KubeWideConfig
 	Kubernetes     *kubernetes.Kubernetes
 
+	session *kubeconfig.Session
+
 	genericclioptions.IOStreams
 }
 
-func newNamespaceOptions(s genericclioptions.IOStreams) *NamespaceOptions {
-	configAccess := clientcmd.NewDefaultPathOptions()
-	c, err := configAccess.GetStartingConfig()
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(PreFlightExitCode)
+func newNamespaceOptions(s genericclioptions.IOStreams, configAccess *clientcmd.PathOptions) *NamespaceOptions {
+	return &NamespaceOptions{
+		PahtOptions: configAccess,
+		session:     kubeconfig.CurrentSession(),
+		IOStreams:   s,
 	}
+}
 
-	kw, err := config.NewKubeWideConfig()
-	if err != nil {
+// complete loads the kubeconfig and the internal file, it must be
+// called after the flags are parsed to honor the --kubeconfig flag
+func (o *NamespaceOptions) complete() {
+	if err := o.load(); err != nil {
 		fmt.Println(err)
 		os.Exit(PreFlightExitCode)
 	}
+}
 
-	k, err := kubernetes.NewKubernetes()
+func (o *NamespaceOptions) load() error {
+	c, err := o.PahtOptions.GetStartingConfig()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(PreFlightExitCode)
+		return err
 	}
 
-	return &NamespaceOptions{
-		Config:         c,
-		PahtOptions:    configAccess,
-		KubeWideConfig: kw,
-		Kubernetes:     k,
-		IOStreams:      s,
+	kw, err := config.NewKubeWideConfig()
+	if err != nil {
+		return err
 	}
+	kw.MigrateNamespaceHistory(c.CurrentContext)
+
+	o.Config = c
+	o.KubeWideConfig = kw
+
+	return nil
 }
 
 // NewCmdNamespace creates a command object for the namespace actions
-func NewCmdNamespace(streams genericclioptions.IOStreams) *cobra.Command {
-	o := newNamespaceOptions(streams)
+func NewCmdNamespace(streams genericclioptions.IOStreams, configAccess *clientcmd.PathOptions) *cobra.Command {
+	o := newNamespaceOptions(streams, configAccess)
 
 	cmd := &cobra.Command{
-		Use:     "ns",
-		Aliases: []string{"n", "namespace"},
-		Short:   "Manage the namespaces",
-		Args:    cobra.MaximumNArgs(1),
-		Example: nsExamples,
+		Use:               "ns",
+		Aliases:           []string{"n", "namespace"},
+		Short:             "Manage the namespaces",
+		Args:              cobra.MaximumNArgs(1),
+		Example:           nsExamples,
+		ValidArgsFunction: o.completeNamespaceArg,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			l := len(args)
+			o.complete()
+
+			if o.History {
+				printHistory(o.Out, "NAMESPACE", o.KubeWideConfig.Context(o.Config.CurrentContext).NamespaceHistory, o.NoHeaders)
+				return nil
+			}
+
+			// The client is created only when it is needed, so a broken
+			// current context does not prevent kw from starting
+			k, err := kubernetes.NewKubernetes(o.PahtOptions.LoadingRules)
+			if err != nil {
+				return err
+			}
+			o.Kubernetes = k
 
 			if l == 0 && !o.Interactive {
 				err := o.list()
@@ -96,16 +127,7 @@ func NewCmdNamespace(streams genericclioptions.IOStreams) *cobra.Command {
 				var namespace string
 
 				if o.Interactive {
-					ns, err := o.Kubernetes.Namespaces()
-					if err != nil {
-						return err
-					}
-
-					keys := make([]string, 0, len(ns))
-					for _, n := range ns {
-						keys = append(keys, n.GetName())
-					}
-					namespace, err = common.InteractiveMode(keys)
+					namespace, err = selectNamespace(o.Kubernetes, o.PahtOptions.LoadingRules, o.KubeWideConfig, o.Config.CurrentContext)
 					if err != nil {
 						return err
 					}
@@ -122,28 +144,69 @@ func NewCmdNamespace(streams genericclioptions.IOStreams) *cobra.Command {
 
 	cmd.Flags().BoolVarP(&o.Interactive, "interactive", "i", o.Interactive, "Enable interactive mode.")
 	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "Does not print the headers.")
+	cmd.Flags().BoolVar(&o.History, "history", o.History, "List the namespaces recently used in the current context.")
 
 	return cmd
 }
 
+// selectNamespace displays the namespaces of the cluster in the
+// interactive mode, ordered by their usage in the given context. The
+// previews use a client with a timeout, so an unreachable cluster
+// does not freeze the interactive mode.
+func selectNamespace(k *kubernetes.Kubernetes, rules *clientcmd.ClientConfigLoadingRules, kw *config.KubeWideConfig, context string) (string, error) {
+	ns, err := k.Namespaces()
+	if err != nil {
+		return "", err
+	}
+
+	preview, err := kubernetes.NewKubernetesWithTimeout(rules, context, previewTimeout)
+	if err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(ns))
+	for _, n := range ns {
+		keys = append(keys, n.GetName())
+	}
+	config.SortByUsage(keys, config.SortFrecency, time.Now(), func(n string) *config.UsageStat {
+		return kw.NamespaceUsage(context, n)
+	})
+
+	return common.InteractiveMode(keys, common.WithPreview(namespacePreview(func(name string) string {
+		return describeResources(preview, name)
+	}, ns)))
+}
+
 func (o *NamespaceOptions) set(ns string) error {
 	if ns == PreviousIdentifier {
-		ns = o.KubeWideConfig.PreviousNamespace()
+		ns = o.KubeWideConfig.Context(o.Config.CurrentContext).PreviousNamespace
+		if ns == "" {
+			return fmt.Errorf("no previous namespace in the context: %s", o.Config.CurrentContext)
+		}
+	} else if n, ok := parseHistoryArg(ns); ok {
+		ns = o.KubeWideConfig.HistoryNamespace(o.Config.CurrentContext, n)
+		if ns == "" {
+			return fmt.Errorf("namespace not found in the history of the context %s: %s%d", o.Config.CurrentContext, HistoryIdentifier, n)
+		}
 	}
 
 	// Preserve information about the current context to write it
 	// to the internal file. In the future, we can use this information
 	// to define to previous context and namespace.
 	context, ok := o.Config.Contexts[o.Config.CurrentContext]
-	if ok {
-		if context.Namespace != ns {
-			o.KubeWideConfig.SetPreviousNamespace(context.Namespace)
-		}
+	if !ok {
+		return fmt.Errorf("current context not found: %s", o.Config.CurrentContext)
+	}
+
+	if context.Namespace != ns {
+		o.KubeWideConfig.SetPreviousNamespace(context.Namespace)
 	}
 
+	o.KubeWideConfig.SetContextNamespace(o.Config.CurrentContext, context.Namespace, ns)
 	context.Namespace = ns
+	o.KubeWideConfig.RecordUsage(o.Config.CurrentContext, ns, time.Now())
 
-	err := clientcmd.ModifyConfig(o.PahtOptions, *o.Config, true)
+	err := modifyConfig(o.PahtOptions, o.Config, o.session, nil)
 	if err != nil {
 		return fmt.Errorf("error when modifying the current namespace: %w", err)
 	}
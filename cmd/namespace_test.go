@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/leocomelli/kw/pkg/config"
+	"github.com/stretchr/testify/assert"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestNamespaceSetWithoutCurrentContext(t *testing.T) {
+
+	c := clientcmdapi.NewConfig()
+	c.Contexts["minikube"] = &clientcmdapi.Context{}
+	c.CurrentContext = "deleted"
+
+	o := &NamespaceOptions{Config: c, KubeWideConfig: &config.KubeWideConfig{Previous: map[string]string{}}}
+
+	assert.EqualError(t, o.set("kube-system"), "current context not found: deleted")
+	assert.Empty(t, o.KubeWideConfig.Contexts)
+}
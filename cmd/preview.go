@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/leocomelli/kw/pkg/kubernetes"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// previewTimeout bounds the requests made to describe a namespace
+const previewTimeout = 3 * time.Second
+
+// previewContext describes a context or an alias in the interactive mode
+func (o *ContextOptions) previewContext(option string) string {
+	name := o.KubeWideConfig.ResolveAlias(option)
+
+	ctx, ok := o.Config.Contexts[name]
+	if !ok {
+		return ""
+	}
+
+	lastUsed := "never"
+	if u := o.KubeWideConfig.ContextUsage(name); u != nil {
+		lastUsed = fmt.Sprintf("%s ago", duration.HumanDuration(time.Since(u.LastUsed)))
+	}
+
+	namespace := ctx.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Context:    %s\n", name)
+	fmt.Fprintf(&b, "Server:     %s\n", kubeconfig.Server(o.Config, name))
+	fmt.Fprintf(&b, "Cluster:    %s\n", ctx.Cluster)
+	fmt.Fprintf(&b, "User:       %s\n", ctx.AuthInfo)
+	fmt.Fprintf(&b, "Auth:       %s\n", kubeconfig.AuthType(o.Config.AuthInfos[ctx.AuthInfo]))
+	fmt.Fprintf(&b, "Namespace:  %s\n", namespace)
+	fmt.Fprintf(&b, "Last used:  %s\n", lastUsed)
+	if aliases := o.KubeWideConfig.ContextAliases(name); len(aliases) > 0 {
+		fmt.Fprintf(&b, "Aliases:    %s\n", strings.Join(aliases, ", "))
+	}
+	if tags := o.KubeWideConfig.Context(name).Tags; len(tags) > 0 {
+		fmt.Fprintf(&b, "Tags:       %s\n", labels.Set(tags).String())
+	}
+	if o.KubeWideConfig.Context(name).Protected {
+		fmt.Fprintf(&b, "Protected:  yes\n")
+	}
+
+	return b.String()
+}
+
+// namespacePreview returns a function that describes a namespace in the
+// interactive mode. The resources are fetched in background because they
+// require requests to the cluster, a placeholder is shown until the
+// description is cached.
+func namespacePreview(fetch func(name string) string, namespaces []core.Namespace) func(string) string {
+	byName := make(map[string]core.Namespace, len(namespaces))
+	for _, n := range namespaces {
+		byName[n.GetName()] = n
+	}
+
+	var mu sync.Mutex
+	cache := make(map[string]string)
+
+	return func(name string) string {
+		n, ok := byName[name]
+		if !ok {
+			return ""
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		r, ok := cache[name]
+		if !ok {
+			r = "Pods:       loading…\n"
+			cache[name] = r
+			go func() {
+				p := fetch(name)
+				mu.Lock()
+				cache[name] = p
+				mu.Unlock()
+			}()
+		}
+
+		return describeNamespace(n) + r
+	}
+}
+
+// describeNamespace describes the namespace without requests to the cluster
+func describeNamespace(n core.Namespace) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Namespace:  %s\n", n.GetName())
+	fmt.Fprintf(&b, "Status:     %s\n", n.Status.Phase)
+	fmt.Fprintf(&b, "Age:        %s\n", translateTimestampSince(n.GetCreationTimestamp()))
+
+	lbls := labels.Set(n.GetLabels()).String()
+	if lbls == "" {
+		lbls = "<none>"
+	}
+	fmt.Fprintf(&b, "Labels:     %s\n", lbls)
+
+	return b.String()
+}
+
+// describeResources describes the pods and the quotas of the namespace, both
+// requests are made concurrently and share the same deadline
+func describeResources(k *kubernetes.Kubernetes, name string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), previewTimeout)
+	defer cancel()
+
+	type podsResult struct {
+		pods []core.Pod
+		err  error
+	}
+	type quotasResult struct {
+		quotas []core.ResourceQuota
+		err    error
+	}
+
+	podsCh := make(chan podsResult, 1)
+	go func() {
+		pods, err := k.Pods(name)
+		podsCh <- podsResult{pods, err}
+	}()
+
+	quotasCh := make(chan quotasResult, 1)
+	go func() {
+		quotas, err := k.ResourceQuotas(name)
+		quotasCh <- quotasResult{quotas, err}
+	}()
+
+	var b strings.Builder
+
+	var pr podsResult
+	select {
+	case pr = <-podsCh:
+	case <-ctx.Done():
+		pr.err = ctx.Err()
+	}
+
+	if pr.err != nil {
+		fmt.Fprintf(&b, "Pods:       %v\n", pr.err)
+	} else {
+		phases := make(map[string]int)
+		for _, p := range pr.pods {
+			phases[string(p.Status.Phase)]++
+		}
+		fmt.Fprintf(&b, "Pods:       %d %s\n", len(pr.pods), formatCounts(phases))
+	}
+
+	var qr quotasResult
+	select {
+	case qr = <-quotasCh:
+	case <-ctx.Done():
+		qr.err = ctx.Err()
+	}
+
+	if qr.err != nil {
+		fmt.Fprintf(&b, "Quotas:     %v\n", qr.err)
+		return b.String()
+	}
+
+	if len(qr.quotas) == 0 {
+		fmt.Fprintf(&b, "Quotas:     <none>\n")
+	}
+	for _, q := range qr.quotas {
+		fmt.Fprintf(&b, "Quota:      %s\n", q.GetName())
+
+		resources := make([]string, 0, len(q.Status.Hard))
+		for r := range q.Status.Hard {
+			resources = append(resources, string(r))
+		}
+		sort.Strings(resources)
+
+		for _, r := range resources {
+			hard := q.Status.Hard[core.ResourceName(r)]
+			used := q.Status.Used[core.ResourceName(r)]
+			fmt.Fprintf(&b, "  %s: %s/%s\n", r, used.String(), hard.String())
+		}
+	}
+
+	return b.String()
+}
+
+// formatCounts returns the counts sorted by key, e.g. (Pending: 1, Running: 3)
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s: %d", k, counts[k]))
+	}
+
+	return fmt.Sprintf("(%s)", strings.Join(parts, ", "))
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamespacePreview(t *testing.T) {
+
+	release := make(chan struct{})
+	calls := 0
+	fetch := func(name string) string {
+		calls++
+		<-release
+		return "Pods:       2 (Running: 2)\n"
+	}
+
+	ns := []core.Namespace{{ObjectMeta: meta.ObjectMeta{Name: "payments"}}}
+	preview := namespacePreview(fetch, ns)
+
+	assert.Equal(t, "", preview("unknown"))
+
+	p := preview("payments")
+	assert.Contains(t, p, "Namespace:  payments\n")
+	assert.Contains(t, p, "Pods:       loading…\n")
+	assert.Contains(t, preview("payments"), "Pods:       loading…\n")
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		return !strings.Contains(preview("payments"), "loading…")
+	}, time.Second, 10*time.Millisecond)
+
+	p = preview("payments")
+	assert.Contains(t, p, "Namespace:  payments\n")
+	assert.Contains(t, p, "Pods:       2 (Running: 2)\n")
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/leocomelli/kw/pkg/config"
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const defaultPromptFormat = "{{if .Protected}}!{{end}}{{.Context}}:{{.Namespace}}"
+
+var (
+	promptExamples = templates.Examples(`
+		# Show the context and namespace in the bash prompt
+		PS1='[$(kw prompt --shell bash)] \$ '
+
+		# Show the context and namespace in the zsh prompt
+		setopt PROMPT_SUBST
+		PROMPT='[$(kw prompt --shell zsh)] %# '
+
+		# Show the context and namespace in the fish prompt
+		function fish_right_prompt; kw prompt --shell fish; end
+
+		# Show the context and namespace using a starship custom module
+		# [custom.kw]
+		# command = "kw prompt --shell starship"
+		# when = true
+
+		# Use a custom format, the fields are Context, Namespace, Alias, Protected and Session
+		kw prompt --format '{{if .Alias}}{{.Alias}}{{else}}{{.Context}}{{end}}/{{.Namespace}}'
+		`)
+
+	// escapeSequence matches the color sequences written by the print functions
+	escapeSequence = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
+
+// PromptOptions contains the input to the prompt command.
+type PromptOptions struct {
+	Format      string
+	Shell       string
+	NoCache     bool
+	PahtOptions *clientcmd.PathOptions
+
+	genericclioptions.IOStreams
+}
+
+// PromptInfo contains the fields available in the prompt format
+type PromptInfo struct {
+	Context   string
+	Namespace string
+	Alias     string
+	Protected bool
+	Session   bool
+}
+
+// NewCmdPrompt creates a command object that prints a shell prompt segment
+func NewCmdPrompt(streams genericclioptions.IOStreams, configAccess *clientcmd.PathOptions) *cobra.Command {
+	o := &PromptOptions{PahtOptions: configAccess, IOStreams: streams}
+
+	cmd := &cobra.Command{
+		Use:     "prompt",
+		Short:   "Print the current context and namespace for the shell prompt",
+		Args:    cobra.NoArgs,
+		Example: promptExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// errors are printed on every prompt, the usage does not help
+			cmd.SilenceUsage = true
+
+			switch o.Shell {
+			case "bash", "zsh", "fish", "starship", "none":
+			default:
+				return fmt.Errorf("invalid shell %q, allowed values: bash, zsh, fish, starship, none", o.Shell)
+			}
+
+			return o.run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Format, "format", o.Format, "Go template used to print the prompt, the prompt-format setting is used by default.")
+	cmd.Flags().StringVar(&o.Shell, "shell", "none", "Shell that renders the prompt, one of: bash|zsh|fish|starship|none.")
+	cmd.Flags().BoolVar(&o.NoCache, "no-cache", o.NoCache, "Do not use the cached prompt.")
+
+	return cmd
+}
+
+// ExecutePrompt runs the prompt command on its own. The prompt runs on
+// every prompt render, so it does not build the whole command tree.
+func ExecutePrompt(args []string, in io.Reader, out, err io.Writer) error {
+	useLegacyKubeconfigEnv()
+
+	configAccess := clientcmd.NewDefaultPathOptions()
+	cmd := NewCmdPrompt(genericclioptions.IOStreams{In: in, Out: out, ErrOut: err}, configAccess)
+	cmd.Use = "kw prompt"
+	addKubeconfigFlag(cmd, configAccess)
+	cmd.SetArgs(args)
+
+	return cmd.Execute()
+}
+
+// run prints the prompt. The output only changes when the kubeconfig
+// files or the internal file change, or when a time-limited switch
+// expires, so it is cached using their modification times and the
+// deadline of the switch to avoid parsing them on every prompt render.
+func (o *PromptOptions) run() error {
+	kwPath, err := config.Path()
+	if err != nil {
+		return err
+	}
+
+	files := append(o.PahtOptions.GetLoadingPrecedence(), kwPath)
+	key := o.cacheKey(files)
+	cache := promptCachePath()
+	now := time.Now()
+
+	if !o.NoCache {
+		if out, ok := readPromptCache(cache, key, now); ok {
+			fmt.Fprint(o.Out, out)
+			return nil
+		}
+	}
+
+	out, until, err := o.render(now)
+	if err != nil {
+		return err
+	}
+
+	// the cache is an optimization, the prompt is printed anyway
+	_ = writePromptCache(cache, key, until, out)
+
+	fmt.Fprint(o.Out, out)
+
+	return nil
+}
+
+// render reads the kubeconfig and the internal file and executes the
+// format, it also returns when the prompt changes because the time-limited
+// switch of the shell expires, zero when it does not expire
+func (o *PromptOptions) render(now time.Time) (string, time.Time, error) {
+	c, err := o.PahtOptions.GetStartingConfig()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	kw, err := config.NewKubeWideConfig()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	// an expired switch is only reverted by the next kw command, the
+	// prompt already shows the context it switches back to
+	current := c.CurrentContext
+	var until time.Time
+	if e := kw.Expiry(sessionPath(kubeconfig.CurrentSession())); e != nil && e.Context == current {
+		if !e.Expired(now) {
+			until = e.Deadline
+		} else if _, ok := c.Contexts[e.Revert]; ok || e.Revert == "" {
+			current = e.Revert
+		}
+	}
+
+	ctx, ok := c.Contexts[current]
+	if !ok {
+		return "", until, nil
+	}
+
+	format := o.Format
+	if format == "" {
+		format = kw.Settings.PromptFormat
+	}
+	if format == "" {
+		format = defaultPromptFormat
+	}
+
+	tmpl, err := template.New("prompt").Parse(format)
+	if err != nil {
+		return "", until, fmt.Errorf("invalid prompt format: %w", err)
+	}
+
+	info := PromptInfo{
+		Context:   current,
+		Namespace: ctx.Namespace,
+		Protected: kw.Context(current).Protected,
+		Session:   kubeconfig.CurrentSession() != nil,
+	}
+	if info.Namespace == "" {
+		info.Namespace = config.DefaultNamespace
+	}
+	if aliases := kw.ContextAliases(current); len(aliases) > 0 {
+		info.Alias = aliases[0]
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, info); err != nil {
+		return "", until, fmt.Errorf("error executing the prompt format: %w", err)
+	}
+	out := buf.String()
+
+	if color := protectedColor(kw, current); color != nil && o.Shell != "starship" {
+		out = color(out)
+	}
+
+	return wrapEscapes(out, o.Shell), until, nil
+}
+
+// wrapEscapes marks the color sequences as non-printing characters,
+// otherwise bash and zsh miscalculate the prompt width. Bash does not
+// decode \[ and \] in the output of a command substitution, so the
+// readline markers are used instead.
+func wrapEscapes(s, shell string) string {
+	switch shell {
+	case "bash":
+		return escapeSequence.ReplaceAllString(s, "\x01$0\x02")
+	case "zsh":
+		return escapeSequence.ReplaceAllString(s, `%{$0%}`)
+	case "starship":
+		return escapeSequence.ReplaceAllString(s, "")
+	}
+	return s
+}
+
+// cacheKey identifies the inputs of the prompt, it changes when any
+// of the files is modified, created or removed
+func (o *PromptOptions) cacheKey(files []string) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00", o.Format, o.Shell, os.Getenv(kubeconfig.SessionEnv))
+	for _, f := range files {
+		if fi, err := os.Stat(f); err == nil {
+			fmt.Fprintf(h, "%s\x00%d\x00%d\x00", f, fi.ModTime().UnixNano(), fi.Size())
+		} else {
+			fmt.Fprintf(h, "%s\x00-\x00", f)
+		}
+	}
+
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+func promptCachePath() string {
+	return filepath.Join(cacheDir(), "prompt")
+}
+
+// readPromptCache returns the cached prompt when it was stored with the key
+// and it has not expired
+func readPromptCache(path, key string, now time.Time) (string, bool) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	kv := strings.SplitN(string(b), "\n", 3)
+	if len(kv) != 3 || kv[0] != key {
+		return "", false
+	}
+
+	until, err := strconv.ParseInt(kv[1], 10, 64)
+	if err != nil || (until != 0 && !now.Before(time.Unix(0, until))) {
+		return "", false
+	}
+
+	return kv[2], true
+}
+
+// writePromptCache stores the prompt with the key and the time it
+// expires, a zero time never expires
+func writePromptCache(path, key string, until time.Time, out string) error {
+	var n int64
+	if !until.IsZero() {
+		n = until.UnixNano()
+	}
+	return writeCacheFile(path, fmt.Sprintf("%s\n%d\n%s", key, n, out))
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leocomelli/kw/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestWrapEscapes(t *testing.T) {
+
+	s := "\x1b[1;31mprod:default\x1b[0m"
+
+	tests := []struct {
+		Shell    string
+		Expected string
+	}{
+		{"bash", "\x01\x1b[1;31m\x02prod:default\x01\x1b[0m\x02"},
+		{"zsh", "%{\x1b[1;31m%}prod:default%{\x1b[0m%}"},
+		{"fish", s},
+		{"starship", "prod:default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Shell, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, wrapEscapes(s, tt.Shell))
+		})
+	}
+}
+
+func TestPromptCache(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	kubeconfig := filepath.Join(dir, "config")
+	c := clientcmdapi.NewConfig()
+	c.Contexts["minikube"] = &clientcmdapi.Context{Namespace: "kube-system"}
+	c.CurrentContext = "minikube"
+	assert.NoError(t, clientcmd.WriteToFile(*c, kubeconfig))
+
+	os.Setenv("KW_CONFIG", filepath.Join(dir, "kw.yml"))
+	defer os.Unsetenv("KW_CONFIG")
+
+	po := clientcmd.NewDefaultPathOptions()
+	po.LoadingRules.ExplicitPath = kubeconfig
+
+	out := &bytes.Buffer{}
+	o := &PromptOptions{Shell: "none", PahtOptions: po, IOStreams: genericclioptions.IOStreams{Out: out}}
+
+	now := time.Now()
+	rendered, until, err := o.render(now)
+	assert.NoError(t, err)
+	assert.Equal(t, "minikube:kube-system", rendered)
+	assert.True(t, until.IsZero())
+
+	cache := filepath.Join(dir, "cache", "prompt")
+	key := o.cacheKey([]string{kubeconfig})
+	assert.NoError(t, writePromptCache(cache, key, until, rendered))
+
+	cached, ok := readPromptCache(cache, key, now)
+	assert.True(t, ok)
+	assert.Equal(t, rendered, cached)
+
+	// the prompt of a time-limited switch expires with it
+	assert.NoError(t, writePromptCache(cache, key, now.Add(time.Minute), rendered))
+	_, ok = readPromptCache(cache, key, now)
+	assert.True(t, ok)
+	_, ok = readPromptCache(cache, key, now.Add(time.Minute))
+	assert.False(t, ok)
+
+	c.CurrentContext = ""
+	assert.NoError(t, clientcmd.WriteToFile(*c, kubeconfig))
+	_, ok = readPromptCache(cache, o.cacheKey([]string{kubeconfig}), now)
+	assert.False(t, ok)
+}
+
+func TestPromptExpiredSwitch(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	kubeconfig := filepath.Join(dir, "config")
+	c := clientcmdapi.NewConfig()
+	c.Contexts["prod"] = &clientcmdapi.Context{Namespace: "payments"}
+	c.Contexts["staging"] = &clientcmdapi.Context{}
+	c.CurrentContext = "prod"
+	assert.NoError(t, clientcmd.WriteToFile(*c, kubeconfig))
+
+	os.Setenv("KW_CONFIG", filepath.Join(dir, "kw.yml"))
+	defer os.Unsetenv("KW_CONFIG")
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	deadline := time.Now().Add(time.Hour)
+	kw, err := config.NewKubeWideConfig()
+	assert.NoError(t, err)
+	kw.SetExpiry(config.Expiry{Context: "prod", Revert: "staging", Deadline: deadline})
+	assert.NoError(t, kw.Write())
+
+	po := clientcmd.NewDefaultPathOptions()
+	po.LoadingRules.ExplicitPath = kubeconfig
+
+	out := &bytes.Buffer{}
+	o := &PromptOptions{Shell: "none", PahtOptions: po, IOStreams: genericclioptions.IOStreams{Out: out}}
+
+	rendered, until, err := o.render(deadline.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, "prod:payments", rendered)
+	assert.True(t, deadline.Equal(until))
+
+	// the prompt shows the context the next kw command switches back to
+	rendered, until, err = o.render(deadline)
+	assert.NoError(t, err)
+	assert.Equal(t, "staging:default", rendered)
+	assert.True(t, until.IsZero())
+
+	// the switch has not been reverted by the prompt
+	data, err := ioutil.ReadFile(filepath.Join(dir, "kw.yml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "revert: staging")
+
+	// the prompt cached before the deadline is not used after it
+	assert.NoError(t, o.run())
+	assert.Equal(t, "prod:payments", out.String())
+	_, ok := readPromptCache(promptCachePath(), o.cacheKey(append(po.GetLoadingPrecedence(), filepath.Join(dir, "kw.yml"))), deadline)
+	assert.False(t, ok)
+}
+
+func BenchmarkExecutePrompt(b *testing.B) {
+
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(b, err)
+	defer os.RemoveAll(dir)
+
+	kubeconfig := filepath.Join(dir, "config")
+	c := clientcmdapi.NewConfig()
+	c.Contexts["minikube"] = &clientcmdapi.Context{Namespace: "kube-system"}
+	c.CurrentContext = "minikube"
+	assert.NoError(b, clientcmd.WriteToFile(*c, kubeconfig))
+
+	os.Setenv("KW_CONFIG", filepath.Join(dir, "kw.yml"))
+	defer os.Unsetenv("KW_CONFIG")
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	tests := []struct {
+		TestName string
+		Args     []string
+	}{
+		{"cached", []string{"--kubeconfig", kubeconfig, "--shell", "bash"}},
+		{"no cache", []string{"--kubeconfig", kubeconfig, "--shell", "bash", "--no-cache"}},
+	}
+
+	for _, tt := range tests {
+		b.Run(tt.TestName, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := ExecutePrompt(tt.Args, nil, ioutil.Discard, ioutil.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"fmt"
 	"io"
+	"os"
 
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 const (
@@ -12,8 +15,24 @@ const (
 	PreFlightExitCode = 2
 	// PreviousIdentifier defines that the previous value should be used
 	PreviousIdentifier = "-"
+	// HistoryIdentifier defines the prefix used to refer to a history entry, e.g. @2
+	HistoryIdentifier = "@"
+
+	// legacyConfigEnv is the environment variable used to define
+	// the kubeconfig file before KUBECONFIG was supported
+	legacyConfigEnv = "K8S_CONFIG"
 )
 
+// ExitError is returned by the commands whose output has already been
+// written, the process must only exit with the code
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
 // NewCmdKubeWide creates the `kw` command and its nested children.
 func NewCmdKubeWide(in io.Reader, out, err io.Writer) *cobra.Command {
 	ioStreams := genericclioptions.IOStreams{In: in, Out: out, ErrOut: err}
@@ -24,10 +43,37 @@ func NewCmdKubeWide(in io.Reader, out, err io.Writer) *cobra.Command {
 		Long:  ``,
 	}
 
-	cmds.AddCommand(NewCmdContext(ioStreams))
+	useLegacyKubeconfigEnv()
+
+	// All commands share the same loading rules, so the kubeconfig files
+	// are discovered in the same way as kubectl does
+	configAccess := clientcmd.NewDefaultPathOptions()
+	addKubeconfigFlag(cmds, configAccess)
+
+	cmds.AddCommand(NewCmdContext(ioStreams, configAccess))
 	cmds.AddCommand(NewCmdKubectl(ioStreams))
-	cmds.AddCommand(NewCmdNamespace(ioStreams))
-	cmds.AddCommand(NewCmdLogs(ioStreams))
+	cmds.AddCommand(NewCmdNamespace(ioStreams, configAccess))
+	cmds.AddCommand(NewCmdLogs(ioStreams, configAccess))
+	cmds.AddCommand(NewCmdShell(ioStreams, configAccess))
+	cmds.AddCommand(NewCmdPrompt(ioStreams, configAccess))
+	cmds.AddCommand(NewCmdExecIn(ioStreams, configAccess))
 
 	return cmds
 }
+
+// useLegacyKubeconfigEnv exports K8S_CONFIG as KUBECONFIG when the latter
+// is not set, so the existing setups keep working. It is deprecated.
+func useLegacyKubeconfigEnv() {
+	if os.Getenv(clientcmd.RecommendedConfigPathEnvVar) != "" {
+		return
+	}
+
+	if p := os.Getenv(legacyConfigEnv); p != "" {
+		os.Setenv(clientcmd.RecommendedConfigPathEnvVar, p)
+	}
+}
+
+// addKubeconfigFlag defines the flag that overrides the kubeconfig files
+func addKubeconfigFlag(cmd *cobra.Command, configAccess *clientcmd.PathOptions) {
+	cmd.PersistentFlags().StringVar(&configAccess.LoadingRules.ExplicitPath, "kubeconfig", "", "Path to the kubeconfig file to use instead of $KUBECONFIG or ~/.kube/config.")
+}
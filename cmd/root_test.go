@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestUseLegacyKubeconfigEnv(t *testing.T) {
+
+	defer os.Setenv(clientcmd.RecommendedConfigPathEnvVar, os.Getenv(clientcmd.RecommendedConfigPathEnvVar))
+	defer os.Unsetenv(legacyConfigEnv)
+
+	tests := []struct {
+		TestName   string
+		Kubeconfig string
+		Legacy     string
+		Expected   string
+	}{
+		{"legacy only", "", "/tmp/legacy", "/tmp/legacy"},
+		{"kubeconfig wins", "/tmp/config", "/tmp/legacy", "/tmp/config"},
+		{"none", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			os.Setenv(clientcmd.RecommendedConfigPathEnvVar, tt.Kubeconfig)
+			os.Setenv(legacyConfigEnv, tt.Legacy)
+
+			useLegacyKubeconfigEnv()
+			assert.Equal(t, tt.Expected, os.Getenv(clientcmd.RecommendedConfigPathEnvVar))
+		})
+	}
+}
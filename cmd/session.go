@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// modifyConfig persists the changes of the config. Inside a session the
+// current context and its namespace are only written to the session file,
+// the other changes, e.g. a renamed context, are written to the kubeconfig
+// files shared with the other shells. The renamed contexts are given from
+// their old name to the new one.
+func modifyConfig(po *clientcmd.PathOptions, c *clientcmdapi.Config, s *kubeconfig.Session, renamed map[string]string) error {
+	if s == nil {
+		return clientcmd.ModifyConfig(po, *c, true)
+	}
+
+	access := s.ConfigAccess(po.LoadingRules)
+	files, err := access.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+
+	shared := c.DeepCopy()
+	shared.CurrentContext = renamedContext(shared, renamed, files.CurrentContext)
+	for name, ctx := range files.Contexts {
+		sc, ok := shared.Contexts[renamedContext(shared, renamed, name)]
+		if !ok {
+			continue
+		}
+		if s.Owns(sc.LocationOfOrigin) || sc == shared.Contexts[c.CurrentContext] {
+			sc.LocationOfOrigin = ctx.LocationOfOrigin
+			sc.Namespace = ctx.Namespace
+		}
+	}
+	// the contexts created from the session context are written
+	// to the default kubeconfig file
+	for _, ctx := range shared.Contexts {
+		if s.Owns(ctx.LocationOfOrigin) {
+			ctx.LocationOfOrigin = ""
+		}
+	}
+
+	if err := clientcmd.ModifyConfig(access, *shared, true); err != nil {
+		return err
+	}
+
+	return s.Apply(c, c.CurrentContext)
+}
+
+// renamedContext returns the name of a context of the files in the config,
+// following its rename. It returns empty when the context was deleted.
+func renamedContext(c *clientcmdapi.Config, renamed map[string]string, name string) string {
+	if newName, ok := renamed[name]; ok {
+		name = newName
+	}
+	if _, ok := c.Contexts[name]; !ok {
+		return ""
+	}
+
+	return name
+}
+
+// sessionFiles returns the kubeconfig files a new session is layered on
+func sessionFiles(po *clientcmd.PathOptions, s *kubeconfig.Session) []string {
+	if s != nil {
+		return s.Files
+	}
+	if po.IsExplicitFile() {
+		return []string{po.GetExplicitFile()}
+	}
+	return po.GetLoadingPrecedence()
+}
+
+// printSessionEnv writes the shell commands that export the session
+func printSessionEnv(w io.Writer, s *kubeconfig.Session) {
+	for _, e := range s.Env() {
+		kv := strings.SplitN(e, "=", 2)
+		fmt.Fprintf(w, "export %s='%s'\n", kv[0], strings.ReplaceAll(kv[1], "'", `'\''`))
+	}
+}
+
+// runInSession runs a command using the session kubeconfig and returns
+// its exit code. The interrupt signals are handled by the command, kw
+// only has to stay alive to remove the session file when it exits.
+func runInSession(streams genericclioptions.IOStreams, s *kubeconfig.Session, name string, args ...string) (int, error) {
+	c := exec.Command(name, args...)
+	c.Stdin = streams.In
+	c.Stdout = streams.Out
+	c.Stderr = streams.ErrOut
+	c.Env = append(os.Environ(), s.Env()...)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sig)
+
+	if err := c.Start(); err != nil {
+		return 0, fmt.Errorf("error running %s: %w", name, err)
+	}
+
+	// the terminal already sends the interrupt to the whole process
+	// group, the other signals are only received by kw
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sg := <-sig:
+				if sg != os.Interrupt {
+					_ = c.Process.Signal(sg)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	if err := c.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			// follow the shell convention for the commands killed by a signal
+			if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+				return 128 + int(ws.Signal()), nil
+			}
+			return exitErr.ExitCode(), nil
+		}
+		return 0, fmt.Errorf("error running %s: %w", name, err)
+	}
+
+	return 0, nil
+}
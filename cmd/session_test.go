@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestModifyConfigInSession(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yaml")}
+	for i, name := range []string{"a", "b"} {
+		c := clientcmdapi.NewConfig()
+		c.Clusters[name] = &clientcmdapi.Cluster{Server: "https://" + name}
+		c.AuthInfos[name] = &clientcmdapi.AuthInfo{Token: name}
+		c.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+		c.CurrentContext = "a"
+		assert.NoError(t, clientcmd.WriteToFile(*c, files[i]))
+	}
+
+	s := &kubeconfig.Session{Path: filepath.Join(dir, "session.yml"), Files: files}
+	const envVar = "KW_TEST_KUBECONFIG"
+	os.Setenv(envVar, strings.TrimPrefix(s.Env()[0], "KUBECONFIG="))
+	defer os.Unsetenv(envVar)
+
+	po := clientcmd.NewDefaultPathOptions()
+	po.EnvVar = envVar
+
+	load := func() *clientcmdapi.Config {
+		c, err := po.GetStartingConfig()
+		assert.NoError(t, err)
+		return c
+	}
+
+	// the current context and its namespace are only changed in the session
+	c := load()
+	c.CurrentContext = "b"
+	c.Contexts["b"].Namespace = "kube-system"
+	assert.NoError(t, modifyConfig(po, c, s, nil))
+
+	c = load()
+	assert.Equal(t, "b", c.CurrentContext)
+	assert.Equal(t, "kube-system", c.Contexts["b"].Namespace)
+
+	b, err := clientcmd.LoadFromFile(files[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "a", b.CurrentContext)
+	assert.Equal(t, "", b.Contexts["b"].Namespace)
+
+	// renaming the session context modifies the file that defines it
+	assert.NoError(t, kubeconfig.RenameContext(c, "b", "bb"))
+	assert.NoError(t, modifyConfig(po, c, s, map[string]string{"b": "bb"}))
+
+	b, err = clientcmd.LoadFromFile(files[1])
+	assert.NoError(t, err)
+	assert.NotContains(t, b.Contexts, "b")
+	assert.Equal(t, "", b.Contexts["bb"].Namespace)
+
+	c = load()
+	assert.Equal(t, "bb", c.CurrentContext)
+	assert.Equal(t, "kube-system", c.Contexts["bb"].Namespace)
+	assert.NotContains(t, c.Contexts, "b")
+}
+
+func TestRunInSession(t *testing.T) {
+
+	out := &bytes.Buffer{}
+	streams := genericclioptions.IOStreams{In: &bytes.Buffer{}, Out: out, ErrOut: out}
+	s := &kubeconfig.Session{Path: "/tmp/session.yml"}
+
+	code, err := runInSession(streams, s, "sh", "-c", "echo $KUBECONFIG; exit 3")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "/tmp/session.yml\n", out.String())
+
+	_, err = runInSession(streams, s, "kw-nonexistent-command")
+	assert.Error(t, err)
+}
+
+func TestRenamedContext(t *testing.T) {
+
+	c := clientcmdapi.NewConfig()
+	c.Contexts["bb"] = &clientcmdapi.Context{Cluster: "b", AuthInfo: "b"}
+	c.Contexts["b-copy"] = &clientcmdapi.Context{Cluster: "b", AuthInfo: "b"}
+	c.Contexts["a"] = &clientcmdapi.Context{Cluster: "a", AuthInfo: "a"}
+
+	tests := []struct {
+		TestName string
+		Renamed  map[string]string
+		Name     string
+		Expected string
+	}{
+		{"kept", nil, "a", "a"},
+		{"renamed", map[string]string{"b": "bb"}, "b", "bb"},
+		{"deleted with a context using the same cluster and user", nil, "b", ""},
+		{"no current context", nil, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, renamedContext(c, tt.Renamed, tt.Name))
+		})
+	}
+}
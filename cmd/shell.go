@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/leocomelli/kw/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	shellExamples = templates.Examples(`
+		# Start a shell whose context changes do not affect other shells
+		kw shell
+
+		# Start a shell using the given context and namespace
+		kw shell minikube:kube-system
+		`)
+)
+
+// NewCmdShell creates a command object that starts a shell using a session kubeconfig
+func NewCmdShell(streams genericclioptions.IOStreams, configAccess *clientcmd.PathOptions) *cobra.Command {
+	o := newContextOptions(streams, configAccess)
+
+	cmd := &cobra.Command{
+		Use:               "shell [context[:namespace]]",
+		Short:             "Start a shell whose context and namespace changes are isolated from other shells",
+		Args:              cobra.MaximumNArgs(1),
+		Example:           shellExamples,
+		ValidArgsFunction: o.completeContextArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.complete()
+
+			if o.session != nil {
+				return fmt.Errorf("already running in a kw session: %s", o.session.Path)
+			}
+
+			s, err := kubeconfig.NewSession(sessionFiles(o.PahtOptions, nil), os.Getpid())
+			if err != nil {
+				return err
+			}
+			defer s.Remove()
+
+			o.session = s
+			if len(args) > 0 {
+				var ctx, ns string
+				ctx, ns, err = o.parseContextArg(args[0])
+				if err == nil {
+					err = o.set(ctx, ns)
+				}
+			} else {
+				err = s.Apply(o.Config, o.Config.CurrentContext)
+			}
+			if err != nil {
+				return err
+			}
+
+			sh := os.Getenv("SHELL")
+			if sh == "" {
+				sh = "/bin/sh"
+			}
+
+			// the exit code of the shell is the one of its last command
+			_, err = runInSession(o.IOStreams, s, sh)
+			return err
+		},
+	}
+
+	return cmd
+}
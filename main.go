@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -8,9 +9,25 @@ import (
 )
 
 func main() {
-	cmd := cmd.NewCmdKubeWide(os.Stdin, os.Stdout, os.Stderr)
+	// the prompt is rendered by the shell all the time, it is handled
+	// before the command tree, which includes kubectl, is built
+	if len(os.Args) > 1 && os.Args[1] == "prompt" {
+		if err := cmd.ExecutePrompt(os.Args[2:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	root := cmd.NewCmdKubeWide(os.Stdin, os.Stdout, os.Stderr)
+
+	// a time-limited switch is reverted before the command uses the context
+	cmd.RevertExpired(root, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
 
-	if err := cmd.Execute(); err != nil {
+	if err := root.Execute(); err != nil {
+		var exitErr *cmd.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -1,15 +1,68 @@
 package common
 
 import (
+	"io"
+	"os"
+
 	"github.com/ktr0731/go-fuzzyfinder"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
+// InteractiveOption configures the interactive mode
+type InteractiveOption func(*interactiveConfig)
+
+type interactiveConfig struct {
+	preview func(string) string
+	header  string
+}
+
+// WithPreview displays a preview window with the text returned by fn
+// for the option under the cursor
+func WithPreview(fn func(option string) string) InteractiveOption {
+	return func(c *interactiveConfig) {
+		c.preview = fn
+	}
+}
+
+// WithHeader displays a header line above the options
+func WithHeader(header string) InteractiveOption {
+	return func(c *interactiveConfig) {
+		c.header = header
+	}
+}
+
 // InteractiveMode displays a UI that provide fuzzy finding against to the options passed
-func InteractiveMode(options []string) (string, error) {
-	idx, err := fuzzyfinder.Find(options, func(i int) string { return options[i] })
+func InteractiveMode(options []string, opts ...InteractiveOption) (string, error) {
+	c := &interactiveConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	var findOpts []fuzzyfinder.Option
+	if c.preview != nil {
+		findOpts = append(findOpts, fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+			if i == -1 {
+				return ""
+			}
+			return c.preview(options[i])
+		}))
+	}
+
+	if c.header != "" {
+		findOpts = append(findOpts, fuzzyfinder.WithHeader(c.header))
+	}
+
+	idx, err := fuzzyfinder.Find(options, func(i int) string { return options[i] }, findOpts...)
 	if err != nil {
 		return "", err
 	}
 
 	return options[idx], nil
 }
+
+// IsTerminal reports whether the input is a terminal, the interactive
+// mode is only available when the input is a terminal
+func IsTerminal(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
@@ -1,6 +1,10 @@
 package common
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 // PrintFn is a wrapper function to print using an ansi color
 type PrintFn func(...interface{}) string
@@ -11,6 +15,19 @@ type PrintColor struct {
 	ColorIdx int
 }
 
+// colors are the available ansi colors, in the order they are returned by Get
+var colors = []struct {
+	name     string
+	sequence string
+}{
+	{"green", "\033[1;32m%s\033[0m"},
+	{"yellow", "\033[1;33m%s\033[0m"},
+	{"blue", "\033[1;34m%s\033[0m"},
+	{"magenta", "\033[1;35m%s\033[0m"},
+	{"teal", "\033[1;36m%s\033[0m"},
+	{"red", "\033[1;31m%s\033[0m"},
+}
+
 // NewPrintColor creates a new color manager
 func NewPrintColor() *PrintColor {
 	pc := &PrintColor{}
@@ -20,13 +37,9 @@ func NewPrintColor() *PrintColor {
 }
 
 func (p *PrintColor) load() {
-	p.Colors = []PrintFn{
-		PrintClr("\033[1;32m%s\033[0m"), // Green
-		PrintClr("\033[1;33m%s\033[0m"), // Yellow
-		PrintClr("\033[1;34m%s\033[0m"), // Purple
-		PrintClr("\033[1;35m%s\033[0m"), // Magenta
-		PrintClr("\033[1;36m%s\033[0m"), // Teal
-		PrintClr("\033[1;31m%s\033[0m"), // Red
+	p.Colors = make([]PrintFn, 0, len(colors))
+	for _, c := range colors {
+		p.Colors = append(p.Colors, PrintClr(c.sequence))
 	}
 }
 
@@ -42,6 +55,17 @@ func (p *PrintColor) Get() PrintFn {
 	return fn
 }
 
+// GetByName returns the color with the given name
+func (p *PrintColor) GetByName(name string) (PrintFn, error) {
+	for _, c := range colors {
+		if c.name == name {
+			return PrintClr(c.sequence), nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid color %q, allowed values: %s", name, strings.Join(ColorNames(), ", "))
+}
+
 // GetNoColor disable ansi color
 func (p *PrintColor) GetNoColor() PrintFn {
 	return func(args ...interface{}) string {
@@ -49,6 +73,17 @@ func (p *PrintColor) GetNoColor() PrintFn {
 	}
 }
 
+// ColorNames returns the sorted names of the available colors
+func ColorNames() []string {
+	names := make([]string, 0, len(colors))
+	for _, c := range colors {
+		names = append(names, c.name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
 // PrintClr returns a the wrapper func using a given color
 func PrintClr(color string) PrintFn {
 	sprint := func(args ...interface{}) string {
@@ -15,3 +15,15 @@ func TestColorIndex(t *testing.T) {
 
 	assert.Equal(t, pc.ColorIdx, 0)
 }
+
+func TestColorByName(t *testing.T) {
+
+	pc := NewPrintColor()
+
+	fn, err := pc.GetByName("red")
+	assert.NoError(t, err)
+	assert.Equal(t, "\033[1;31mola\033[0m", fn("ola"))
+
+	_, err = pc.GetByName("black")
+	assert.Error(t, err)
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Prompt writes the message and returns the line read from the input
+func Prompt(in io.Reader, out io.Writer, msg string) (string, error) {
+	fmt.Fprint(out, msg)
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("error reading the answer: %w", err)
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+// Confirm asks a yes or no question, the default answer is no
+func Confirm(in io.Reader, out io.Writer, msg string) (bool, error) {
+	answer, err := Prompt(in, out, fmt.Sprintf("%s [y/N]: ", msg))
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
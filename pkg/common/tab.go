@@ -1,6 +1,8 @@
 package common
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"strings"
@@ -9,11 +11,12 @@ import (
 
 const tab = '\t'
 
-// TabPrint writes data to a specific writer using the tabular format
+// TabPrint writes data to a specific writer using the tabular format. The
+// padding keeps the columns apart when a cell fills the whole tab width.
 func TabPrint(w io.Writer, headers []string, data [][]string) {
 
 	tw := &tabwriter.Writer{}
-	tw.Init(w, 0, 8, 0, tab, 0)
+	tw.Init(w, 0, 8, 1, tab, 0)
 
 	if len(headers) > 0 {
 		fmt.Fprintln(tw, strings.Join(headers, string(tab)))
@@ -25,3 +28,28 @@ func TabPrint(w io.Writer, headers []string, data [][]string) {
 
 	_ = tw.Flush()
 }
+
+// TabPrintFn writes data using the tabular format, each row is printed
+// using the print function with the same index when it is not nil.
+// The colors are applied after the alignment, so they do not change
+// the width of the columns.
+func TabPrintFn(w io.Writer, headers []string, data [][]string, fns []PrintFn) {
+	var buf bytes.Buffer
+	TabPrint(&buf, headers, data)
+
+	// the header line is not related to any print function
+	row := 0
+	if len(headers) > 0 {
+		row = -1
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if row >= 0 && row < len(fns) && fns[row] != nil {
+			line = fns[row](line)
+		}
+		fmt.Fprintln(w, line)
+		row++
+	}
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTabPrintFn(t *testing.T) {
+
+	var buf bytes.Buffer
+	upper := func(args ...interface{}) string { return "[" + args[0].(string) + "]" }
+
+	TabPrintFn(&buf, []string{"A", "B"}, [][]string{{"1", "2"}, {"3", "4"}}, []PrintFn{nil, upper})
+
+	assert.Equal(t, "A\tB\n1\t2\n[3\t4]\n", buf.String())
+}
+
+func TestTabPrint(t *testing.T) {
+
+	tests := []struct {
+		TestName string
+		Headers  []string
+		Data     [][]string
+		Expected string
+	}{
+		{"short cells", []string{"NAME", "KIND"}, [][]string{{"a", "b"}}, "NAME\tKIND\na\tb\n"},
+		{"cell as wide as the tab", []string{"SEVERITY", "KIND"}, [][]string{{"error", "context"}}, "SEVERITY\tKIND\nerror\t\tcontext\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			var buf bytes.Buffer
+			TabPrint(&buf, tt.Headers, tt.Data)
+			assert.Equal(t, tt.Expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"time"
+)
+
+// Expiry records a time-limited context switch, once the deadline passes
+// the current context is switched back to the revert context. The session
+// is empty when the switch modified the kubeconfig files instead of a
+// session kubeconfig.
+type Expiry struct {
+	Session  string    `yaml:"session,omitempty"`
+	Context  string    `yaml:"context"`
+	Revert   string    `yaml:"revert"`
+	Deadline time.Time `yaml:"deadline"`
+}
+
+// Expired reports whether the deadline has passed
+func (e *Expiry) Expired(now time.Time) bool {
+	return !now.Before(e.Deadline)
+}
+
+// Expiry returns the time-limited switch of the session, otherwise nil
+func (c *KubeWideConfig) Expiry(session string) *Expiry {
+	for i := range c.Expiries {
+		if c.Expiries[i].Session == session {
+			return &c.Expiries[i]
+		}
+	}
+	return nil
+}
+
+// SetExpiry records a time-limited switch, replacing the previous
+// one of the same session
+func (c *KubeWideConfig) SetExpiry(e Expiry) {
+	if cur := c.Expiry(e.Session); cur != nil {
+		*cur = e
+		return
+	}
+	c.Expiries = append(c.Expiries, e)
+}
+
+// RemoveExpiry removes the time-limited switch of the session
+func (c *KubeWideConfig) RemoveExpiry(session string) {
+	var expiries []Expiry
+	for _, e := range c.Expiries {
+		if e.Session != session {
+			expiries = append(expiries, e)
+		}
+	}
+	c.Expiries = expiries
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestExpiries(t *testing.T) {
+
+	deadline := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	c := &KubeWideConfig{}
+	c.SetExpiry(Expiry{Context: "prod", Revert: "staging", Deadline: deadline})
+	c.SetExpiry(Expiry{Session: "/tmp/kw-session", Context: "prod", Revert: "dev", Deadline: deadline})
+	c.SetExpiry(Expiry{Context: "prod-us", Revert: "staging", Deadline: deadline})
+	assert.Len(t, c.Expiries, 2)
+	assert.Equal(t, "prod-us", c.Expiry("").Context)
+	assert.Nil(t, c.Expiry("/tmp/other"))
+
+	assert.False(t, c.Expiry("").Expired(deadline.Add(-time.Second)))
+	assert.True(t, c.Expiry("").Expired(deadline))
+
+	data, err := yaml.Marshal(c)
+	assert.NoError(t, err)
+	decoded := &KubeWideConfig{}
+	assert.NoError(t, yaml.Unmarshal(data, decoded))
+	assert.Equal(t, c.Expiries, decoded.Expiries)
+
+	c.RenameContext("staging", "stg")
+	assert.Equal(t, "stg", c.Expiry("").Revert)
+
+	c.RemoveContext("prod")
+	assert.Len(t, c.Expiries, 1)
+	c.RemoveContext("stg")
+	assert.Equal(t, "", c.Expiry("").Revert)
+
+	c.RemoveExpiry("")
+	assert.Empty(t, c.Expiries)
+}
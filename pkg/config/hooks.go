@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Hook defines the shell commands that run before and after switching
+// to a context. A hook applies to the context with the given name and to
+// the contexts whose tags match the selector, when both are defined the
+// context must match both.
+type Hook struct {
+	Context  string        `yaml:"context,omitempty"`
+	Selector string        `yaml:"selector,omitempty"`
+	Pre      string        `yaml:"pre,omitempty"`
+	Post     string        `yaml:"post,omitempty"`
+	Timeout  time.Duration `yaml:"timeout,omitempty"`
+}
+
+// Matches reports whether the hook applies to the context
+func (h Hook) Matches(context string, tags map[string]string) (bool, error) {
+	if h.Context == "" && h.Selector == "" {
+		return false, nil
+	}
+
+	if h.Context != "" && h.Context != context {
+		return false, nil
+	}
+
+	if h.Selector != "" {
+		selector, err := labels.Parse(h.Selector)
+		if err != nil {
+			return false, fmt.Errorf("invalid hook selector %q: %w", h.Selector, err)
+		}
+		if !selector.Matches(labels.Set(tags)) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// ContextHooks returns the hooks that apply to a context, in the order
+// they are declared
+func (c *KubeWideConfig) ContextHooks(context string) ([]Hook, error) {
+	var hooks []Hook
+	for _, h := range c.Hooks {
+		ok, err := h.Matches(context, c.Context(context).Tags)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			hooks = append(hooks, h)
+		}
+	}
+
+	return hooks, nil
+}
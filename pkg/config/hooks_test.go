@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestContextHooks(t *testing.T) {
+
+	data := `
+hooks:
+- context: eks-prod
+  pre: aws sso login --profile prod
+  timeout: 2m
+- selector: env=prod
+  pre: vpn-check
+- selector: env=prod
+  context: gke-prod
+  post: echo switched
+`
+	c := &KubeWideConfig{}
+	assert.NoError(t, yaml.Unmarshal([]byte(data), c))
+	assert.Equal(t, 2*time.Minute, c.Hooks[0].Timeout)
+
+	c.SetTag("eks-prod", "env", "prod")
+	c.SetTag("gke-prod", "env", "prod")
+
+	tests := []struct {
+		TestName string
+		Context  string
+		Expected []Hook
+	}{
+		{"by name and tag", "eks-prod", []Hook{c.Hooks[0], c.Hooks[1]}},
+		{"by name and selector", "gke-prod", []Hook{c.Hooks[1], c.Hooks[2]}},
+		{"no hooks", "minikube", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			hooks, err := c.ContextHooks(tt.Context)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Expected, hooks)
+		})
+	}
+
+	c.Hooks = append(c.Hooks, Hook{Selector: "env in (prod", Pre: "true"})
+	_, err := c.ContextHooks("minikube")
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
@@ -15,12 +16,60 @@ const (
 
 	previousContextKey   = "context"
 	previousNamespaceKey = "namespace"
+
+	// HistorySize defines how many entries are kept in the history
+	HistorySize = 10
+
+	// DefaultNamespace is used when a context does not define a namespace
+	DefaultNamespace = "default"
 )
 
 // KubeWideConfig represents the internal data
 type KubeWideConfig struct {
-	pathname string            `yaml:"-"`
-	Previous map[string]string `yaml:"previous"`
+	pathname string                    `yaml:"-"`
+	Previous map[string]string         `yaml:"previous"`
+	History  History                   `yaml:"history,omitempty"`
+	Aliases  map[string]string         `yaml:"aliases,omitempty"`
+	Contexts map[string]*ContextConfig `yaml:"contexts,omitempty"`
+	Usage    Usage                     `yaml:"usage,omitempty"`
+	Settings Settings                  `yaml:"settings,omitempty"`
+	Hooks    []Hook                    `yaml:"hooks,omitempty"`
+	Expiries []Expiry                  `yaml:"expiries,omitempty"`
+}
+
+// Settings holds the kw preferences
+type Settings struct {
+	// ChainNamespace selects the namespace right after
+	// the context in the interactive mode
+	ChainNamespace bool `yaml:"chain-namespace,omitempty"`
+	// PromptFormat is the template used by the prompt command
+	PromptFormat string `yaml:"prompt-format,omitempty"`
+	// RestoreNamespace switches to the last namespace used in
+	// a context when no namespace is given
+	RestoreNamespace bool `yaml:"restore-namespace,omitempty"`
+}
+
+// ContextConfig holds the kw settings of a context
+type ContextConfig struct {
+	Tags           map[string]string `yaml:"tags,omitempty"`
+	Protected      bool              `yaml:"protected,omitempty"`
+	Color          string            `yaml:"color,omitempty"`
+	RequireConfirm bool              `yaml:"require-confirm,omitempty"`
+
+	LastNamespace     string `yaml:"last-namespace,omitempty"`
+	PreviousNamespace string `yaml:"previous-namespace,omitempty"`
+	// NamespaceHistory keeps the namespaces recently used in the
+	// context, the first entry is the most recent one
+	NamespaceHistory []string `yaml:"namespace-history,omitempty"`
+}
+
+// History keeps the most recently used contexts, the first entry is
+// the most recent one. The namespaces are kept per context.
+type History struct {
+	Contexts []string `yaml:"contexts,omitempty"`
+	// Namespaces is the global namespace history written by the previous
+	// versions, it is only read to be moved to a context
+	Namespaces []string `yaml:"namespaces,omitempty"`
 }
 
 // NewKubeWideConfig creates a new internal configuration
@@ -49,15 +98,22 @@ func NewKubeWideConfig() (*KubeWideConfig, error) {
 }
 
 func (c *KubeWideConfig) path() error {
-	c.pathname = os.Getenv("KW_CONFIG")
-	if c.pathname == "" {
-		var err error
-		c.pathname, err = homedir.Expand(defaultPath)
-		if err != nil {
-		}
+	var err error
+	c.pathname, err = Path()
+	return err
+}
+
+// Path returns the location of the internal config file
+func Path() (string, error) {
+	if p := os.Getenv("KW_CONFIG"); p != "" {
+		return p, nil
 	}
 
-	return nil
+	p, err := homedir.Expand(defaultPath)
+	if err != nil {
+		return "", fmt.Errorf("error getting the kw config path: %w", err)
+	}
+	return p, nil
 }
 
 func (c *KubeWideConfig) read() error {
@@ -94,12 +150,279 @@ func (c *KubeWideConfig) PreviousNamespace() string {
 	return ""
 }
 
-// SetPreviousContext sets the previous context
-func (c *KubeWideConfig) SetPreviousContext(name string) {
-	c.Previous[previousContextKey] = name
+// SetPreviousContext sets the previous context and records it in the
+// history, the current context is removed from the history because
+// jumping back to it would not change anything
+func (c *KubeWideConfig) SetPreviousContext(previous, current string) {
+	c.Previous[previousContextKey] = previous
+	c.History.Contexts = removeHistory(pushHistory(c.History.Contexts, previous), current)
 }
 
 // SetPreviousNamespace sets the previous namespace
 func (c *KubeWideConfig) SetPreviousNamespace(name string) {
 	c.Previous[previousNamespaceKey] = name
 }
+
+// HistoryContext returns the context used n steps ago, otherwise empty
+func (c *KubeWideConfig) HistoryContext(n int) string {
+	return historyEntry(c.History.Contexts, n)
+}
+
+// HistoryNamespace returns the namespace used n steps ago in the
+// context, otherwise empty
+func (c *KubeWideConfig) HistoryNamespace(context string, n int) string {
+	return historyEntry(c.Context(context).NamespaceHistory, n)
+}
+
+// SetAlias defines an alternative name for a context
+func (c *KubeWideConfig) SetAlias(alias, context string) {
+	if c.Aliases == nil {
+		c.Aliases = make(map[string]string)
+	}
+	c.Aliases[alias] = context
+}
+
+// RemoveAlias removes an alternative name
+func (c *KubeWideConfig) RemoveAlias(alias string) {
+	delete(c.Aliases, alias)
+}
+
+// ResolveAlias returns the context referenced by an alias, otherwise the name itself
+func (c *KubeWideConfig) ResolveAlias(name string) string {
+	if ctx, ok := c.Aliases[name]; ok {
+		return ctx
+	}
+	return name
+}
+
+// ContextAliases returns the sorted aliases of a context
+func (c *KubeWideConfig) ContextAliases(context string) []string {
+	var aliases []string
+	for a, ctx := range c.Aliases {
+		if ctx == context {
+			aliases = append(aliases, a)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// Context returns the settings of a context, the returned value
+// is not stored when the context has no settings yet
+func (c *KubeWideConfig) Context(name string) *ContextConfig {
+	if cc, ok := c.Contexts[name]; ok {
+		return cc
+	}
+	return &ContextConfig{}
+}
+
+// MigrateNamespaceHistory moves the global namespace history written by the
+// previous versions to the history of the context, after its own entries
+func (c *KubeWideConfig) MigrateNamespaceHistory(context string) {
+	if context == "" || len(c.History.Namespaces) == 0 {
+		return
+	}
+
+	cc := c.context(context)
+	for _, ns := range c.History.Namespaces {
+		if !contains(cc.NamespaceHistory, ns) && len(cc.NamespaceHistory) < HistorySize {
+			cc.NamespaceHistory = append(cc.NamespaceHistory, ns)
+		}
+	}
+	c.History.Namespaces = nil
+}
+
+// context returns the stored settings of a context, creating them if needed
+func (c *KubeWideConfig) context(name string) *ContextConfig {
+	if c.Contexts == nil {
+		c.Contexts = make(map[string]*ContextConfig)
+	}
+
+	cc, ok := c.Contexts[name]
+	if !ok {
+		cc = &ContextConfig{}
+		c.Contexts[name] = cc
+	}
+	return cc
+}
+
+// SetContextNamespace records the namespace used in a context, the replaced
+// namespace becomes the previous one of the context and is added to its
+// history. An empty namespace is recorded as the default namespace.
+func (c *KubeWideConfig) SetContextNamespace(context, previous, current string) {
+	if previous == "" {
+		previous = DefaultNamespace
+	}
+	if current == "" {
+		current = DefaultNamespace
+	}
+
+	cc := c.context(context)
+	if previous != current {
+		cc.PreviousNamespace = previous
+		cc.NamespaceHistory = pushHistory(cc.NamespaceHistory, previous)
+	}
+	cc.NamespaceHistory = removeHistory(cc.NamespaceHistory, current)
+	cc.LastNamespace = current
+}
+
+// SetTag sets a tag of a context
+func (c *KubeWideConfig) SetTag(context, key, value string) {
+	cc := c.context(context)
+	if cc.Tags == nil {
+		cc.Tags = make(map[string]string)
+	}
+	cc.Tags[key] = value
+}
+
+// RemoveTag removes a tag of a context
+func (c *KubeWideConfig) RemoveTag(context, key string) {
+	delete(c.Context(context).Tags, key)
+}
+
+// Protect marks a context as protected, the color is used to highlight
+// it and the switch to it requires a confirmation when requireConfirm is set
+func (c *KubeWideConfig) Protect(context, color string, requireConfirm bool) {
+	cc := c.context(context)
+	cc.Protected = true
+	cc.Color = color
+	cc.RequireConfirm = requireConfirm
+}
+
+// Unprotect removes the protection of a context
+func (c *KubeWideConfig) Unprotect(context string) {
+	if cc, ok := c.Contexts[context]; ok {
+		cc.Protected = false
+		cc.Color = ""
+		cc.RequireConfirm = false
+	}
+}
+
+// RenameContext replaces the references to a renamed context
+func (c *KubeWideConfig) RenameContext(oldName, newName string) {
+	if c.PreviousContext() == oldName {
+		c.Previous[previousContextKey] = newName
+	}
+
+	for a, ctx := range c.Aliases {
+		if ctx == oldName {
+			c.Aliases[a] = newName
+		}
+	}
+
+	if cc, ok := c.Contexts[oldName]; ok {
+		c.Contexts[newName] = cc
+		delete(c.Contexts, oldName)
+	}
+
+	if u, ok := c.Usage.Contexts[oldName]; ok {
+		c.Usage.Contexts[newName] = u
+		delete(c.Usage.Contexts, oldName)
+	}
+
+	if u, ok := c.Usage.Namespaces[oldName]; ok {
+		c.Usage.Namespaces[newName] = u
+		delete(c.Usage.Namespaces, oldName)
+	}
+
+	for i := range c.Expiries {
+		if c.Expiries[i].Context == oldName {
+			c.Expiries[i].Context = newName
+		}
+		if c.Expiries[i].Revert == oldName {
+			c.Expiries[i].Revert = newName
+		}
+	}
+
+	var h []string
+	for _, e := range c.History.Contexts {
+		if e == oldName {
+			e = newName
+		}
+		if !contains(h, e) {
+			h = append(h, e)
+		}
+	}
+	c.History.Contexts = h
+}
+
+// RemoveContext removes the references to a deleted context
+func (c *KubeWideConfig) RemoveContext(name string) {
+	if c.PreviousContext() == name {
+		c.Previous[previousContextKey] = ""
+	}
+
+	for a, ctx := range c.Aliases {
+		if ctx == name {
+			delete(c.Aliases, a)
+		}
+	}
+
+	delete(c.Contexts, name)
+	delete(c.Usage.Contexts, name)
+	delete(c.Usage.Namespaces, name)
+
+	var expiries []Expiry
+	for _, e := range c.Expiries {
+		if e.Context == name {
+			continue
+		}
+		if e.Revert == name {
+			e.Revert = ""
+		}
+		expiries = append(expiries, e)
+	}
+	c.Expiries = expiries
+
+	var h []string
+	for _, e := range c.History.Contexts {
+		if e != name {
+			h = append(h, e)
+		}
+	}
+	c.History.Contexts = h
+}
+
+// pushHistory adds the name at the beginning of the entries, removing
+// any older occurrence and keeping at most HistorySize entries
+func pushHistory(entries []string, name string) []string {
+	if name == "" {
+		return entries
+	}
+
+	h := []string{name}
+	for _, e := range entries {
+		if e != name && len(h) < HistorySize {
+			h = append(h, e)
+		}
+	}
+
+	return h
+}
+
+// removeHistory removes the name from the entries
+func removeHistory(entries []string, name string) []string {
+	var h []string
+	for _, e := range entries {
+		if e != name {
+			h = append(h, e)
+		}
+	}
+	return h
+}
+
+func historyEntry(entries []string, n int) string {
+	if n < 1 || n > len(entries) {
+		return ""
+	}
+	return entries[n-1]
+}
+
+func contains(entries []string, name string) bool {
+	for _, e := range entries {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
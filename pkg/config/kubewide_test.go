@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestPushHistory(t *testing.T) {
+
+	var h []string
+	h = pushHistory(h, "a")
+	h = pushHistory(h, "b")
+	h = pushHistory(h, "")
+	h = pushHistory(h, "a")
+
+	assert.Equal(t, []string{"a", "b"}, h)
+}
+
+func TestPushHistoryBounded(t *testing.T) {
+
+	var h []string
+	for i := 0; i < HistorySize+5; i++ {
+		h = pushHistory(h, fmt.Sprintf("ctx-%d", i))
+	}
+
+	assert.Len(t, h, HistorySize)
+	assert.Equal(t, fmt.Sprintf("ctx-%d", HistorySize+4), h[0])
+}
+
+func TestSetPreviousContextSkipsCurrent(t *testing.T) {
+
+	c := &KubeWideConfig{Previous: map[string]string{}}
+	// a -> b -> a
+	c.SetPreviousContext("a", "b")
+	c.SetPreviousContext("b", "a")
+
+	assert.Equal(t, "b", c.PreviousContext())
+	assert.Equal(t, []string{"b"}, c.History.Contexts)
+}
+
+func TestHistoryEntry(t *testing.T) {
+
+	h := []string{"a", "b"}
+
+	assert.Equal(t, "a", historyEntry(h, 1))
+	assert.Equal(t, "b", historyEntry(h, 2))
+	assert.Equal(t, "", historyEntry(h, 0))
+	assert.Equal(t, "", historyEntry(h, 3))
+}
+
+func TestRenameAndRemoveContext(t *testing.T) {
+
+	c := &KubeWideConfig{Previous: map[string]string{}}
+	c.SetPreviousContext("a", "b")
+	c.SetPreviousContext("b", "c")
+	c.SetPreviousContext("c", "d")
+	c.SetAlias("x", "c")
+	c.SetTag("c", "env", "prod")
+
+	c.RenameContext("c", "a")
+	assert.Equal(t, "a", c.PreviousContext())
+	assert.Equal(t, []string{"a", "b"}, c.History.Contexts)
+	assert.Equal(t, "a", c.ResolveAlias("x"))
+	assert.Equal(t, map[string]string{"env": "prod"}, c.Context("a").Tags)
+
+	c.RemoveContext("a")
+	assert.Equal(t, "", c.PreviousContext())
+	assert.Equal(t, []string{"b"}, c.History.Contexts)
+	assert.Empty(t, c.Aliases)
+	assert.Empty(t, c.Contexts)
+}
+
+func TestAliases(t *testing.T) {
+
+	c := &KubeWideConfig{}
+	c.SetAlias("prod", "gke_project-1234_europe-west1_prod-main")
+	c.SetAlias("main", "gke_project-1234_europe-west1_prod-main")
+
+	assert.Equal(t, "gke_project-1234_europe-west1_prod-main", c.ResolveAlias("prod"))
+	assert.Equal(t, "minikube", c.ResolveAlias("minikube"))
+	assert.Equal(t, []string{"main", "prod"}, c.ContextAliases("gke_project-1234_europe-west1_prod-main"))
+
+	c.RemoveAlias("main")
+	assert.Equal(t, []string{"prod"}, c.ContextAliases("gke_project-1234_europe-west1_prod-main"))
+}
+
+func TestTags(t *testing.T) {
+
+	c := &KubeWideConfig{}
+	assert.Empty(t, c.Context("minikube").Tags)
+
+	c.SetTag("minikube", "env", "dev")
+	c.SetTag("minikube", "team", "payments")
+	c.RemoveTag("minikube", "team")
+	c.RemoveTag("unknown", "team")
+
+	assert.Equal(t, map[string]string{"env": "dev"}, c.Context("minikube").Tags)
+	assert.NotContains(t, c.Contexts, "unknown")
+}
+
+func TestProtect(t *testing.T) {
+
+	c := &KubeWideConfig{}
+	c.Protect("prod", "red", true)
+
+	assert.Equal(t, &ContextConfig{Protected: true, Color: "red", RequireConfirm: true}, c.Context("prod"))
+
+	c.Unprotect("prod")
+	assert.False(t, c.Context("prod").Protected)
+}
+
+func TestSetContextNamespace(t *testing.T) {
+
+	c := &KubeWideConfig{}
+	c.SetContextNamespace("prod", "", "payments")
+	c.SetContextNamespace("staging", "kube-system", "kube-system")
+
+	assert.Equal(t, "payments", c.Context("prod").LastNamespace)
+	assert.Equal(t, DefaultNamespace, c.Context("prod").PreviousNamespace)
+	assert.Equal(t, "kube-system", c.Context("staging").LastNamespace)
+	assert.Equal(t, "", c.Context("staging").PreviousNamespace)
+
+	c.SetContextNamespace("prod", "payments", "orders")
+	assert.Equal(t, "orders", c.Context("prod").LastNamespace)
+	assert.Equal(t, "payments", c.Context("prod").PreviousNamespace)
+
+	// the history of each context is kept apart
+	c.SetContextNamespace("prod", "orders", "payments")
+	assert.Equal(t, []string{"orders", DefaultNamespace}, c.Context("prod").NamespaceHistory)
+	assert.Equal(t, "orders", c.HistoryNamespace("prod", 1))
+	assert.Empty(t, c.Context("staging").NamespaceHistory)
+	assert.Equal(t, "", c.HistoryNamespace("staging", 1))
+}
+
+func TestMigrateNamespaceHistory(t *testing.T) {
+
+	data := `
+history:
+  contexts: [staging]
+  namespaces: [orders, payments, kube-system]
+contexts:
+  prod:
+    namespace-history: [payments]
+`
+	c := &KubeWideConfig{}
+	assert.NoError(t, yaml.Unmarshal([]byte(data), c))
+
+	c.MigrateNamespaceHistory("")
+	assert.Equal(t, []string{"orders", "payments", "kube-system"}, c.History.Namespaces)
+
+	c.MigrateNamespaceHistory("prod")
+	assert.Equal(t, []string{"payments", "orders", "kube-system"}, c.Context("prod").NamespaceHistory)
+	assert.Empty(t, c.History.Namespaces)
+	assert.Equal(t, []string{"staging"}, c.History.Contexts)
+
+	b, err := yaml.Marshal(c)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "namespaces:")
+
+	// the history is migrated once
+	c.MigrateNamespaceHistory("staging")
+	assert.Empty(t, c.Context("staging").NamespaceHistory)
+}
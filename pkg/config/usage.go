@@ -0,0 +1,126 @@
+package config
+
+import (
+	"sort"
+	"time"
+)
+
+// Criteria used to sort by usage
+const (
+	SortFrecency  = "frecency"
+	SortRecent    = "recent"
+	SortFrequency = "frequency"
+)
+
+// Usage keeps how often and how recently the contexts and
+// the namespaces of each context were used
+type Usage struct {
+	Contexts   map[string]*UsageStat            `yaml:"contexts,omitempty"`
+	Namespaces map[string]map[string]*UsageStat `yaml:"namespaces,omitempty"`
+}
+
+// UsageStat contains the usage of a single context or namespace
+type UsageStat struct {
+	Count    int       `yaml:"count"`
+	LastUsed time.Time `yaml:"lastUsed"`
+}
+
+// Frecency combines the frequency and the recency of the usage,
+// the recent uses weigh more than the old ones
+func (s *UsageStat) Frecency(now time.Time) float64 {
+	if s == nil {
+		return 0
+	}
+
+	count := float64(s.Count)
+	switch age := now.Sub(s.LastUsed); {
+	case age < time.Hour:
+		return count * 4
+	case age < 24*time.Hour:
+		return count * 2
+	case age < 7*24*time.Hour:
+		return count / 2
+	default:
+		return count / 4
+	}
+}
+
+// RecordUsage registers the use of a context and its namespace
+func (c *KubeWideConfig) RecordUsage(context, namespace string, now time.Time) {
+	if c.Usage.Contexts == nil {
+		c.Usage.Contexts = make(map[string]*UsageStat)
+	}
+	c.Usage.Contexts[context] = record(c.Usage.Contexts[context], now)
+
+	if namespace == "" {
+		return
+	}
+
+	if c.Usage.Namespaces == nil {
+		c.Usage.Namespaces = make(map[string]map[string]*UsageStat)
+	}
+	if c.Usage.Namespaces[context] == nil {
+		c.Usage.Namespaces[context] = make(map[string]*UsageStat)
+	}
+	c.Usage.Namespaces[context][namespace] = record(c.Usage.Namespaces[context][namespace], now)
+}
+
+// ContextUsage returns the usage of a context, otherwise nil
+func (c *KubeWideConfig) ContextUsage(context string) *UsageStat {
+	return c.Usage.Contexts[context]
+}
+
+// NamespaceUsage returns the usage of a namespace in a context, otherwise nil
+func (c *KubeWideConfig) NamespaceUsage(context, namespace string) *UsageStat {
+	return c.Usage.Namespaces[context][namespace]
+}
+
+// SortByUsage sorts the names in place using the usage returned by fn,
+// the ties are sorted by name. The criteria are frecency, recent and frequency.
+func SortByUsage(names []string, criteria string, now time.Time, fn func(string) *UsageStat) {
+	less := func(a, b *UsageStat) bool {
+		switch criteria {
+		case SortRecent:
+			return lastUsed(a).After(lastUsed(b))
+		case SortFrequency:
+			return count(a) > count(b)
+		default:
+			return a.Frecency(now) > b.Frecency(now)
+		}
+	}
+
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := fn(names[i]), fn(names[j])
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return names[i] < names[j]
+	})
+}
+
+func record(s *UsageStat, now time.Time) *UsageStat {
+	if s == nil {
+		s = &UsageStat{}
+	}
+	s.Count++
+	s.LastUsed = now
+
+	return s
+}
+
+func lastUsed(s *UsageStat) time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	return s.LastUsed
+}
+
+func count(s *UsageStat) int {
+	if s == nil {
+		return 0
+	}
+	return s.Count
+}
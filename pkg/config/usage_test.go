@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrecency(t *testing.T) {
+
+	now := time.Now()
+
+	var missing *UsageStat
+	assert.Equal(t, float64(0), missing.Frecency(now))
+	assert.Equal(t, float64(8), (&UsageStat{Count: 2, LastUsed: now}).Frecency(now))
+	assert.Equal(t, float64(0.5), (&UsageStat{Count: 2, LastUsed: now.Add(-30 * 24 * time.Hour)}).Frecency(now))
+}
+
+func TestSortByUsage(t *testing.T) {
+
+	now := time.Now()
+
+	c := &KubeWideConfig{}
+	c.RecordUsage("old", "", now.Add(-30*24*time.Hour))
+	c.RecordUsage("old", "", now.Add(-30*24*time.Hour))
+	c.RecordUsage("old", "", now.Add(-30*24*time.Hour))
+	c.RecordUsage("recent", "kube-system", now)
+
+	tests := []struct {
+		Criteria string
+		Expected []string
+	}{
+		{"frecency", []string{"recent", "old", "a", "z"}},
+		{"recent", []string{"recent", "old", "a", "z"}},
+		{"frequency", []string{"old", "recent", "a", "z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Criteria, func(t *testing.T) {
+			names := []string{"z", "old", "a", "recent"}
+			SortByUsage(names, tt.Criteria, now, c.ContextUsage)
+			assert.Equal(t, tt.Expected, names)
+		})
+	}
+
+	assert.Equal(t, 1, c.NamespaceUsage("recent", "kube-system").Count)
+	assert.Nil(t, c.NamespaceUsage("old", "default"))
+}
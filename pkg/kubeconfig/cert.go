@@ -0,0 +1,111 @@
+package kubeconfig
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Certificate types of a context
+const (
+	CertCA     = "ca"
+	CertClient = "client"
+)
+
+// Certificate describes a certificate used by a context, Err is set
+// when the certificate cannot be read or decoded
+type Certificate struct {
+	Context  string
+	Type     string
+	Source   string
+	Subject  string
+	Issuer   string
+	NotAfter time.Time
+	Err      error
+}
+
+// ContextCertificates decodes the cluster CA and the client certificates
+// of a context, read from the data fields or from the referenced files
+func ContextCertificates(c *clientcmdapi.Config, context string) []Certificate {
+	ctx, ok := c.Contexts[context]
+	if !ok {
+		return nil
+	}
+
+	var certs []Certificate
+	if cluster, ok := c.Clusters[ctx.Cluster]; ok {
+		certs = append(certs, decodeCertificates(context, CertCA, cluster.CertificateAuthority, cluster.CertificateAuthorityData)...)
+	}
+	if user, ok := c.AuthInfos[ctx.AuthInfo]; ok {
+		certs = append(certs, decodeCertificates(context, CertClient, user.ClientCertificate, user.ClientCertificateData)...)
+	}
+
+	return certs
+}
+
+func decodeCertificates(context, certType, file string, data []byte) []Certificate {
+	source := "data"
+	if len(data) == 0 {
+		if file == "" {
+			return nil
+		}
+
+		var err error
+		source = file
+		data, err = ioutil.ReadFile(file)
+		if err != nil {
+			return []Certificate{{Context: context, Type: certType, Source: source, Err: err}}
+		}
+	}
+
+	parsed, err := ParseCertificates(data)
+	if err != nil {
+		return []Certificate{{Context: context, Type: certType, Source: source, Err: err}}
+	}
+
+	certs := make([]Certificate, 0, len(parsed))
+	for _, p := range parsed {
+		certs = append(certs, Certificate{
+			Context:  context,
+			Type:     certType,
+			Source:   source,
+			Subject:  p.Subject.String(),
+			Issuer:   p.Issuer.String(),
+			NotAfter: p.NotAfter,
+		})
+	}
+
+	return certs
+}
+
+// ParseCertificates decodes the PEM encoded certificates of a
+// certificate-authority-data or client-certificate-data field
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for len(data) > 0 {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+
+	return certs, nil
+}
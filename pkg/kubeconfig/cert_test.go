@@ -0,0 +1,58 @@
+package kubeconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCertificate(t *testing.T, cn string, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestContextCertificates(t *testing.T) {
+
+	notAfter := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	c := newTestConfig()
+	c.Clusters["c1"].CertificateAuthorityData = newTestCertificate(t, "cluster-ca", notAfter)
+	c.AuthInfos["u1"].ClientCertificateData = []byte("garbage")
+	c.AuthInfos["u2"].ClientCertificate = "/nonexistent/client.crt"
+
+	certs := ContextCertificates(c, "a")
+	assert.Len(t, certs, 2)
+	assert.Equal(t, CertCA, certs[0].Type)
+	assert.Equal(t, "CN=cluster-ca", certs[0].Subject)
+	assert.Equal(t, "CN=cluster-ca", certs[0].Issuer)
+	assert.Equal(t, notAfter, certs[0].NotAfter)
+	assert.NoError(t, certs[0].Err)
+	assert.Equal(t, CertClient, certs[1].Type)
+	assert.Error(t, certs[1].Err)
+
+	certs = ContextCertificates(c, "b")
+	assert.Len(t, certs, 1)
+	assert.Equal(t, "/nonexistent/client.crt", certs[0].Source)
+	assert.Error(t, certs[0].Err)
+
+	assert.Empty(t, ContextCertificates(c, "missing"))
+}
@@ -0,0 +1,99 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"sort"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// RenameContext changes the name of a context, the current context
+// is updated when it refers to the renamed context
+func RenameContext(c *clientcmdapi.Config, oldName, newName string) error {
+	ctx, ok := c.Contexts[oldName]
+	if !ok {
+		return fmt.Errorf("context not found: %s", oldName)
+	}
+
+	if _, ok := c.Contexts[newName]; ok {
+		return fmt.Errorf("context already exists: %s", newName)
+	}
+
+	c.Contexts[newName] = ctx
+	delete(c.Contexts, oldName)
+
+	if c.CurrentContext == oldName {
+		c.CurrentContext = newName
+	}
+
+	return nil
+}
+
+// CopyContext creates a new context using the same cluster and user
+// of the source context, the namespace is replaced when it is not empty
+func CopyContext(c *clientcmdapi.Config, src, dst, ns string) error {
+	ctx, ok := c.Contexts[src]
+	if !ok {
+		return fmt.Errorf("context not found: %s", src)
+	}
+
+	if _, ok := c.Contexts[dst]; ok {
+		return fmt.Errorf("context already exists: %s", dst)
+	}
+
+	cp := ctx.DeepCopy()
+	if ns != "" {
+		cp.Namespace = ns
+	}
+	c.Contexts[dst] = cp
+
+	return nil
+}
+
+// DeleteContext removes a context, the current context is unset
+// when it refers to the deleted context
+func DeleteContext(c *clientcmdapi.Config, name string) error {
+	if _, ok := c.Contexts[name]; !ok {
+		return fmt.Errorf("context not found: %s", name)
+	}
+
+	delete(c.Contexts, name)
+
+	if c.CurrentContext == name {
+		c.CurrentContext = ""
+	}
+
+	return nil
+}
+
+// PruneOrphans removes the clusters and users that are not referenced
+// by any context and returns their names
+func PruneOrphans(c *clientcmdapi.Config) ([]string, []string) {
+	clusters := make(map[string]bool)
+	users := make(map[string]bool)
+	for _, ctx := range c.Contexts {
+		clusters[ctx.Cluster] = true
+		users[ctx.AuthInfo] = true
+	}
+
+	var prunedClusters []string
+	for name := range c.Clusters {
+		if !clusters[name] {
+			delete(c.Clusters, name)
+			prunedClusters = append(prunedClusters, name)
+		}
+	}
+
+	var prunedUsers []string
+	for name := range c.AuthInfos {
+		if !users[name] {
+			delete(c.AuthInfos, name)
+			prunedUsers = append(prunedUsers, name)
+		}
+	}
+
+	sort.Strings(prunedClusters)
+	sort.Strings(prunedUsers)
+
+	return prunedClusters, prunedUsers
+}
@@ -0,0 +1,83 @@
+package kubeconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func newTestConfig() *clientcmdapi.Config {
+	c := clientcmdapi.NewConfig()
+	c.Clusters["c1"] = &clientcmdapi.Cluster{Server: "https://c1"}
+	c.Clusters["c2"] = &clientcmdapi.Cluster{Server: "https://c2"}
+	c.AuthInfos["u1"] = &clientcmdapi.AuthInfo{Token: "t1"}
+	c.AuthInfos["u2"] = &clientcmdapi.AuthInfo{Token: "t2"}
+	c.Contexts["a"] = &clientcmdapi.Context{Cluster: "c1", AuthInfo: "u1", Namespace: "default"}
+	c.Contexts["b"] = &clientcmdapi.Context{Cluster: "c2", AuthInfo: "u2"}
+	c.CurrentContext = "a"
+	return c
+}
+
+func TestRenameContext(t *testing.T) {
+
+	c := newTestConfig()
+
+	assert.NoError(t, RenameContext(c, "a", "z"))
+	assert.Contains(t, c.Contexts, "z")
+	assert.NotContains(t, c.Contexts, "a")
+	assert.Equal(t, "z", c.CurrentContext)
+
+	assert.Error(t, RenameContext(c, "a", "y"))
+	assert.Error(t, RenameContext(c, "z", "b"))
+}
+
+func TestCopyContext(t *testing.T) {
+
+	c := newTestConfig()
+
+	assert.NoError(t, CopyContext(c, "a", "a-system", "kube-system"))
+	assert.Equal(t, "kube-system", c.Contexts["a-system"].Namespace)
+	assert.Equal(t, "default", c.Contexts["a"].Namespace)
+	assert.Equal(t, "c1", c.Contexts["a-system"].Cluster)
+
+	assert.Error(t, CopyContext(c, "x", "y", ""))
+	assert.Error(t, CopyContext(c, "a", "b", ""))
+}
+
+func TestDeleteContextAndPrune(t *testing.T) {
+
+	c := newTestConfig()
+
+	assert.NoError(t, DeleteContext(c, "a"))
+	assert.Equal(t, "", c.CurrentContext)
+	assert.Error(t, DeleteContext(c, "a"))
+
+	clusters, users := PruneOrphans(c)
+	assert.Equal(t, []string{"c1"}, clusters)
+	assert.Equal(t, []string{"u1"}, users)
+	assert.Contains(t, c.Clusters, "c2")
+	assert.Contains(t, c.AuthInfos, "u2")
+}
+
+func TestExport(t *testing.T) {
+
+	c := newTestConfig()
+
+	e, err := Export(c, "b", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", e.CurrentContext)
+	assert.Len(t, e.Contexts, 1)
+	assert.Contains(t, e.Clusters, "c2")
+	assert.Contains(t, e.AuthInfos, "u2")
+	assert.Equal(t, "a", c.CurrentContext)
+
+	e, err = Export(c, "b", false)
+	assert.NoError(t, err)
+	assert.Empty(t, e.AuthInfos)
+	assert.Equal(t, "", e.Contexts["b"].AuthInfo)
+	assert.Equal(t, "u2", c.Contexts["b"].AuthInfo)
+
+	_, err = Export(c, "x", true)
+	assert.Error(t, err)
+}
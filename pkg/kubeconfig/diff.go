@@ -0,0 +1,90 @@
+package kubeconfig
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const redacted = "REDACTED"
+
+// Diff returns the unified diff between two configs. The credentials are
+// replaced by a fingerprint, so they are not printed but a changed
+// credential still shows up in the diff.
+func Diff(from, to *clientcmdapi.Config, fromName, toName string) (string, error) {
+	a, err := clientcmd.Write(*redact(from))
+	if err != nil {
+		return "", err
+	}
+
+	b, err := clientcmd.Write(*redact(to))
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(a)),
+		B:        difflib.SplitLines(string(b)),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+}
+
+// redact returns a copy of the config whose credentials are replaced
+// by a fingerprint
+func redact(c *clientcmdapi.Config) *clientcmdapi.Config {
+	c = c.DeepCopy()
+
+	for _, u := range c.AuthInfos {
+		u.ClientCertificateData = redactBytes(u.ClientCertificateData)
+		u.ClientKeyData = redactBytes(u.ClientKeyData)
+		u.Token = redactString(u.Token)
+		u.Password = redactString(u.Password)
+
+		// the auth provider config holds tokens and client secrets, e.g.
+		// the id-token of oidc, the exec plugins receive them in the env
+		if u.AuthProvider != nil {
+			for k, v := range u.AuthProvider.Config {
+				u.AuthProvider.Config[k] = redactString(v)
+			}
+		}
+		if u.Exec != nil {
+			for i := range u.Exec.Env {
+				u.Exec.Env[i].Value = redactString(u.Exec.Env[i].Value)
+			}
+		}
+	}
+
+	for _, cl := range c.Clusters {
+		cl.CertificateAuthorityData = redactBytes(cl.CertificateAuthorityData)
+	}
+
+	return c
+}
+
+func fingerprint(data []byte) string {
+	return fmt.Sprintf("%s%x", redacted, sha256.Sum256(data))[:len(redacted)+8]
+}
+
+func redactString(s string) string {
+	if s == "" {
+		return ""
+	}
+	return fingerprint([]byte(s))
+}
+
+// redactBytes returns the bytes that are written as the fingerprint,
+// the data fields are base64 encoded when the config is written
+func redactBytes(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+
+	b, _ := base64.StdEncoding.DecodeString(fingerprint(data))
+	return b
+}
@@ -0,0 +1,36 @@
+package kubeconfig
+
+import (
+	"fmt"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Export returns a self-contained config with only the given context,
+// its cluster and user. The certificate and key files are inlined, and
+// the user is removed when the credentials should not be shared.
+func Export(c *clientcmdapi.Config, name string, credentials bool) (*clientcmdapi.Config, error) {
+	if _, ok := c.Contexts[name]; !ok {
+		return nil, fmt.Errorf("context not found: %s", name)
+	}
+
+	e := c.DeepCopy()
+	e.CurrentContext = name
+	e.Preferences = clientcmdapi.Preferences{}
+	e.Extensions = nil
+
+	if err := clientcmdapi.MinifyConfig(e); err != nil {
+		return nil, fmt.Errorf("error minifying the context %s: %w", name, err)
+	}
+
+	if !credentials {
+		e.AuthInfos = map[string]*clientcmdapi.AuthInfo{}
+		e.Contexts[name].AuthInfo = ""
+	}
+
+	if err := clientcmdapi.FlattenConfig(e); err != nil {
+		return nil, fmt.Errorf("error inlining the files of the context %s: %w", name, err)
+	}
+
+	return e, nil
+}
@@ -0,0 +1,49 @@
+package kubeconfig
+
+import (
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Authentication types of a user
+const (
+	AuthExec       = "exec"
+	AuthProvider   = "auth-provider"
+	AuthClientCert = "client-certificate"
+	AuthToken      = "token"
+	AuthBasic      = "basic"
+	AuthNone       = "none"
+	AuthUnknown    = "<unknown>"
+)
+
+// AuthType returns how the user authenticates against the cluster
+func AuthType(u *clientcmdapi.AuthInfo) string {
+	switch {
+	case u == nil:
+		return AuthUnknown
+	case u.Exec != nil:
+		return AuthExec
+	case u.AuthProvider != nil:
+		return AuthProvider
+	case u.ClientCertificate != "" || len(u.ClientCertificateData) > 0:
+		return AuthClientCert
+	case u.Token != "" || u.TokenFile != "":
+		return AuthToken
+	case u.Username != "":
+		return AuthBasic
+	default:
+		return AuthNone
+	}
+}
+
+// Server returns the API server URL of a context, otherwise empty
+func Server(c *clientcmdapi.Config, context string) string {
+	ctx, ok := c.Contexts[context]
+	if !ok {
+		return ""
+	}
+
+	if cluster, ok := c.Clusters[ctx.Cluster]; ok {
+		return cluster.Server
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Severities of the lint problems
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem describes an issue found in a kubeconfig entry
+type Problem struct {
+	Severity string
+	Kind     string
+	Name     string
+	Message  string
+}
+
+// Lint inspects the contexts, clusters and users of the config and
+// returns the problems found, sorted by kind and name
+func Lint(c *clientcmdapi.Config) []Problem {
+	var problems []Problem
+	add := func(severity, kind, name, format string, a ...interface{}) {
+		problems = append(problems, Problem{severity, kind, name, fmt.Sprintf(format, a...)})
+	}
+
+	if c.CurrentContext != "" {
+		if _, ok := c.Contexts[c.CurrentContext]; !ok {
+			add(SeverityError, KindContext, c.CurrentContext, "current context does not exist")
+		}
+	}
+
+	for _, name := range sortedKeys(c.Contexts) {
+		ctx := c.Contexts[name]
+		if _, ok := c.Clusters[ctx.Cluster]; !ok {
+			add(SeverityError, KindContext, name, "cluster not found: %q", ctx.Cluster)
+		}
+		// a context without user is valid, e.g. kw ctx export --no-credentials
+		if _, ok := c.AuthInfos[ctx.AuthInfo]; !ok && ctx.AuthInfo != "" {
+			add(SeverityError, KindContext, name, "user not found: %q", ctx.AuthInfo)
+		}
+	}
+
+	servers := make(map[string][]string)
+	for _, name := range sortedKeys(c.Clusters) {
+		cluster := c.Clusters[name]
+		if cluster.Server != "" {
+			servers[cluster.Server] = append(servers[cluster.Server], name)
+		}
+		if cluster.InsecureSkipTLSVerify {
+			add(SeverityWarning, KindCluster, name, "insecure-skip-tls-verify is enabled")
+		}
+		if cluster.CertificateAuthority != "" && !fileExists(cluster.CertificateAuthority) {
+			add(SeverityError, KindCluster, name, "certificate-authority file not found: %s", cluster.CertificateAuthority)
+		}
+		if len(cluster.CertificateAuthorityData) > 0 {
+			if _, err := ParseCertificates(cluster.CertificateAuthorityData); err != nil {
+				add(SeverityError, KindCluster, name, "invalid certificate-authority-data: %v", err)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(c.Clusters) {
+		server := c.Clusters[name].Server
+		if dup := servers[server]; len(dup) > 1 && dup[0] == name {
+			add(SeverityWarning, KindCluster, name, "same server as %s: %s", strings.Join(dup[1:], ", "), server)
+		}
+	}
+
+	for _, name := range sortedKeys(c.AuthInfos) {
+		user := c.AuthInfos[name]
+		if user.ClientCertificate != "" && !fileExists(user.ClientCertificate) {
+			add(SeverityError, KindUser, name, "client-certificate file not found: %s", user.ClientCertificate)
+		}
+		if user.ClientKey != "" && !fileExists(user.ClientKey) {
+			add(SeverityError, KindUser, name, "client-key file not found: %s", user.ClientKey)
+		}
+		if user.TokenFile != "" && !fileExists(user.TokenFile) {
+			add(SeverityError, KindUser, name, "token file not found: %s", user.TokenFile)
+		}
+		if len(user.ClientCertificateData) > 0 {
+			if _, err := ParseCertificates(user.ClientCertificateData); err != nil {
+				add(SeverityError, KindUser, name, "invalid client-certificate-data: %v", err)
+			}
+		}
+		if user.Exec != nil {
+			if _, err := exec.LookPath(user.Exec.Command); err != nil {
+				add(SeverityError, KindUser, name, "exec command not found: %s", user.Exec.Command)
+			}
+		}
+	}
+
+	kinds := map[string]int{KindContext: 0, KindCluster: 1, KindUser: 2}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Kind != problems[j].Kind {
+			return kinds[problems[i].Kind] < kinds[problems[j].Kind]
+		}
+		return problems[i].Name < problems[j].Name
+	})
+
+	return problems
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,34 @@
+package kubeconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestLint(t *testing.T) {
+
+	c := newTestConfig()
+	assert.Empty(t, Lint(c))
+
+	// exported without credentials
+	c.Contexts["y"] = &clientcmdapi.Context{Cluster: "c1"}
+	assert.Empty(t, Lint(c))
+
+	c.Clusters["c3"] = &clientcmdapi.Cluster{Server: "https://c1", InsecureSkipTLSVerify: true, CertificateAuthorityData: []byte("garbage")}
+	c.AuthInfos["u3"] = &clientcmdapi.AuthInfo{ClientCertificate: "/nonexistent/client.crt", Exec: &clientcmdapi.ExecConfig{Command: "kw-nonexistent-plugin"}}
+	c.Contexts["x"] = &clientcmdapi.Context{Cluster: "missing", AuthInfo: "u3"}
+	c.CurrentContext = "gone"
+
+	expected := []Problem{
+		{SeverityError, KindContext, "gone", "current context does not exist"},
+		{SeverityError, KindContext, "x", `cluster not found: "missing"`},
+		{SeverityWarning, KindCluster, "c1", "same server as c3: https://c1"},
+		{SeverityWarning, KindCluster, "c3", "insecure-skip-tls-verify is enabled"},
+		{SeverityError, KindCluster, "c3", "invalid certificate-authority-data: no PEM encoded certificate found"},
+		{SeverityError, KindUser, "u3", "client-certificate file not found: /nonexistent/client.crt"},
+		{SeverityError, KindUser, "u3", "exec command not found: kw-nonexistent-plugin"},
+	}
+	assert.Equal(t, expected, Lint(c))
+}
@@ -0,0 +1,236 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// MergeStrategy defines how the name collisions are resolved
+type MergeStrategy string
+
+const (
+	// MergeRename keeps both entries, the imported one receives a new name
+	MergeRename MergeStrategy = "rename"
+	// MergeOverwrite replaces the existing entry by the imported one
+	MergeOverwrite MergeStrategy = "overwrite"
+	// MergeSkip keeps the existing entry and ignores the imported one
+	MergeSkip MergeStrategy = "skip"
+)
+
+// Kinds of kubeconfig entries
+const (
+	KindCluster = "cluster"
+	KindUser    = "user"
+	KindContext = "context"
+)
+
+// Actions applied to the imported entries
+const (
+	ActionAdded       = "added"
+	ActionOverwritten = "overwritten"
+	ActionRenamed     = "renamed"
+	ActionSkipped     = "skipped"
+	ActionUnchanged   = "unchanged"
+)
+
+// MergeChange describes what happened to an imported entry
+type MergeChange struct {
+	Kind    string
+	Name    string
+	NewName string
+	Action  string
+}
+
+// String returns the change in a diff-like format
+func (m MergeChange) String() string {
+	switch m.Action {
+	case ActionAdded:
+		return fmt.Sprintf("+ %s %s", m.Kind, m.Name)
+	case ActionOverwritten:
+		return fmt.Sprintf("~ %s %s", m.Kind, m.Name)
+	case ActionRenamed:
+		return fmt.Sprintf("+ %s %s (renamed from %s)", m.Kind, m.NewName, m.Name)
+	case ActionSkipped:
+		return fmt.Sprintf("- %s %s (skipped)", m.Kind, m.Name)
+	default:
+		return fmt.Sprintf("= %s %s", m.Kind, m.Name)
+	}
+}
+
+// ParseMergeStrategy validates the name of a strategy
+func ParseMergeStrategy(s string) (MergeStrategy, error) {
+	switch st := MergeStrategy(s); st {
+	case MergeRename, MergeOverwrite, MergeSkip:
+		return st, nil
+	}
+	return "", fmt.Errorf("invalid strategy %q, allowed values: %s, %s, %s", s, MergeRename, MergeOverwrite, MergeSkip)
+}
+
+// Merge imports the clusters, users and contexts from src into dst. An
+// entry collides when the name already exists with a different content,
+// collisions are resolved using the strategy. Contexts that reference a
+// skipped cluster or user are skipped as well.
+func Merge(dst, src *clientcmdapi.Config, strategy MergeStrategy) []MergeChange {
+	var changes []MergeChange
+
+	// a renamed entry must not take the name of another imported entry
+	reservedClusters := keySet(src.Clusters)
+	reservedUsers := keySet(src.AuthInfos)
+	reservedContexts := keySet(src.Contexts)
+
+	clusters := make(map[string]string)
+	for _, name := range sortedKeys(src.Clusters) {
+		cluster := src.Clusters[name].DeepCopy()
+		cluster.LocationOfOrigin = ""
+
+		change, newName := mergeEntry(KindCluster, name, strategy, func(n string) (interface{}, bool) {
+			c, ok := dst.Clusters[n]
+			return c, ok
+		}, reservedClusters, cluster, func(n string) {
+			if c, ok := dst.Clusters[n]; ok {
+				cluster.LocationOfOrigin = c.LocationOfOrigin
+			}
+			dst.Clusters[n] = cluster
+		})
+		clusters[name] = newName
+		changes = append(changes, change)
+	}
+
+	users := make(map[string]string)
+	for _, name := range sortedKeys(src.AuthInfos) {
+		user := src.AuthInfos[name].DeepCopy()
+		user.LocationOfOrigin = ""
+
+		change, newName := mergeEntry(KindUser, name, strategy, func(n string) (interface{}, bool) {
+			u, ok := dst.AuthInfos[n]
+			return u, ok
+		}, reservedUsers, user, func(n string) {
+			if u, ok := dst.AuthInfos[n]; ok {
+				user.LocationOfOrigin = u.LocationOfOrigin
+			}
+			dst.AuthInfos[n] = user
+		})
+		users[name] = newName
+		changes = append(changes, change)
+	}
+
+	for _, name := range sortedKeys(src.Contexts) {
+		ctx := src.Contexts[name].DeepCopy()
+		ctx.LocationOfOrigin = ""
+
+		cluster, clusterOk := clusters[ctx.Cluster]
+		user, userOk := users[ctx.AuthInfo]
+		if (clusterOk && cluster == "") || (userOk && user == "") {
+			changes = append(changes, MergeChange{Kind: KindContext, Name: name, Action: ActionSkipped})
+			continue
+		}
+		if clusterOk {
+			ctx.Cluster = cluster
+		}
+		if userOk {
+			ctx.AuthInfo = user
+		}
+
+		change, _ := mergeEntry(KindContext, name, strategy, func(n string) (interface{}, bool) {
+			c, ok := dst.Contexts[n]
+			return c, ok
+		}, reservedContexts, ctx, func(n string) {
+			if c, ok := dst.Contexts[n]; ok {
+				ctx.LocationOfOrigin = c.LocationOfOrigin
+			}
+			dst.Contexts[n] = ctx
+		})
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// mergeEntry resolves a single entry and returns the change and the name
+// used in the destination, the name is empty when the entry is skipped.
+// A renamed entry receives a name that is neither in the destination
+// nor in the reserved names.
+func mergeEntry(kind, name string, strategy MergeStrategy, lookup func(string) (interface{}, bool), reserved map[string]bool, entry interface{}, store func(string)) (MergeChange, string) {
+	existing, ok := lookup(name)
+	if !ok {
+		store(name)
+		return MergeChange{Kind: kind, Name: name, Action: ActionAdded}, name
+	}
+
+	if sameEntry(existing, entry) {
+		return MergeChange{Kind: kind, Name: name, Action: ActionUnchanged}, name
+	}
+
+	switch strategy {
+	case MergeOverwrite:
+		store(name)
+		return MergeChange{Kind: kind, Name: name, Action: ActionOverwritten}, name
+	case MergeRename:
+		newName := name
+		for i := 2; ok || reserved[newName]; i++ {
+			newName = fmt.Sprintf("%s-%d", name, i)
+			_, ok = lookup(newName)
+		}
+		store(newName)
+		return MergeChange{Kind: kind, Name: name, NewName: newName, Action: ActionRenamed}, newName
+	default:
+		return MergeChange{Kind: kind, Name: name, Action: ActionSkipped}, ""
+	}
+}
+
+// sameEntry compares two entries ignoring the file they were loaded from
+func sameEntry(a, b interface{}) bool {
+	switch x := a.(type) {
+	case *clientcmdapi.Cluster:
+		y := b.(*clientcmdapi.Cluster)
+		return reflect.DeepEqual(stripCluster(x), stripCluster(y))
+	case *clientcmdapi.AuthInfo:
+		y := b.(*clientcmdapi.AuthInfo)
+		return reflect.DeepEqual(stripUser(x), stripUser(y))
+	case *clientcmdapi.Context:
+		y := b.(*clientcmdapi.Context)
+		return reflect.DeepEqual(stripContext(x), stripContext(y))
+	}
+	return false
+}
+
+func stripCluster(c *clientcmdapi.Cluster) *clientcmdapi.Cluster {
+	c = c.DeepCopy()
+	c.LocationOfOrigin = ""
+	c.Extensions = nil
+	return c
+}
+
+func stripUser(u *clientcmdapi.AuthInfo) *clientcmdapi.AuthInfo {
+	u = u.DeepCopy()
+	u.LocationOfOrigin = ""
+	u.Extensions = nil
+	return u
+}
+
+func stripContext(c *clientcmdapi.Context) *clientcmdapi.Context {
+	c = c.DeepCopy()
+	c.LocationOfOrigin = ""
+	c.Extensions = nil
+	return c
+}
+
+func keySet(m interface{}) map[string]bool {
+	keys := make(map[string]bool)
+	for _, k := range sortedKeys(m) {
+		keys[k] = true
+	}
+	return keys
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
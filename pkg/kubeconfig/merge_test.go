@@ -0,0 +1,113 @@
+package kubeconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func newImportConfig() *clientcmdapi.Config {
+	c := clientcmdapi.NewConfig()
+	c.Clusters["c1"] = &clientcmdapi.Cluster{Server: "https://other", LocationOfOrigin: "/tmp/import"}
+	c.Clusters["c3"] = &clientcmdapi.Cluster{Server: "https://c3", LocationOfOrigin: "/tmp/import"}
+	c.AuthInfos["u1"] = &clientcmdapi.AuthInfo{Token: "t1"}
+	c.Contexts["a"] = &clientcmdapi.Context{Cluster: "c1", AuthInfo: "u1"}
+	c.Contexts["c"] = &clientcmdapi.Context{Cluster: "c3", AuthInfo: "u1"}
+	return c
+}
+
+func TestMergeRename(t *testing.T) {
+
+	dst := newTestConfig()
+	changes := Merge(dst, newImportConfig(), MergeRename)
+
+	assert.Equal(t, []MergeChange{
+		{Kind: KindCluster, Name: "c1", NewName: "c1-2", Action: ActionRenamed},
+		{Kind: KindCluster, Name: "c3", Action: ActionAdded},
+		{Kind: KindUser, Name: "u1", Action: ActionUnchanged},
+		{Kind: KindContext, Name: "a", NewName: "a-2", Action: ActionRenamed},
+		{Kind: KindContext, Name: "c", Action: ActionAdded},
+	}, changes)
+
+	assert.Equal(t, "https://c1", dst.Clusters["c1"].Server)
+	assert.Equal(t, "https://other", dst.Clusters["c1-2"].Server)
+	assert.Equal(t, "", dst.Clusters["c3"].LocationOfOrigin)
+	assert.Equal(t, "c1-2", dst.Contexts["a-2"].Cluster)
+}
+
+func TestMergeOverwrite(t *testing.T) {
+
+	dst := newTestConfig()
+	Merge(dst, newImportConfig(), MergeOverwrite)
+
+	assert.Equal(t, "https://other", dst.Clusters["c1"].Server)
+	assert.Equal(t, "", dst.Contexts["a"].Namespace)
+}
+
+func TestMergeSkip(t *testing.T) {
+
+	dst := newTestConfig()
+	changes := Merge(dst, newImportConfig(), MergeSkip)
+
+	assert.Equal(t, ActionSkipped, changes[0].Action)
+	assert.Equal(t, MergeChange{Kind: KindContext, Name: "a", Action: ActionSkipped}, changes[3])
+	assert.Equal(t, "https://c1", dst.Clusters["c1"].Server)
+	assert.Equal(t, "default", dst.Contexts["a"].Namespace)
+	assert.Contains(t, dst.Contexts, "c")
+}
+
+func TestMergeRenameAvoidsImportedNames(t *testing.T) {
+
+	src := newImportConfig()
+	src.Clusters["c1-2"] = &clientcmdapi.Cluster{Server: "https://c1-2"}
+
+	dst := newTestConfig()
+	changes := Merge(dst, src, MergeRename)
+
+	assert.Equal(t, MergeChange{Kind: KindCluster, Name: "c1", NewName: "c1-3", Action: ActionRenamed}, changes[0])
+	assert.Equal(t, "https://other", dst.Clusters["c1-3"].Server)
+	assert.Equal(t, "https://c1-2", dst.Clusters["c1-2"].Server)
+	assert.Equal(t, "c1-3", dst.Contexts["a-2"].Cluster)
+}
+
+func TestDiff(t *testing.T) {
+
+	src := newImportConfig()
+	src.AuthInfos["u1"].Token = "t9"
+	src.AuthInfos["oidc"] = &clientcmdapi.AuthInfo{
+		AuthProvider: &clientcmdapi.AuthProviderConfig{Name: "oidc", Config: map[string]string{
+			"idp-issuer-url": "https://issuer",
+			"client-secret":  "oidc-client-secret",
+			"id-token":       "oidc-id-token",
+			"refresh-token":  "oidc-refresh-token",
+		}},
+	}
+	src.AuthInfos["eks"] = &clientcmdapi.AuthInfo{
+		Exec: &clientcmdapi.ExecConfig{Command: "aws", Env: []clientcmdapi.ExecEnvVar{{Name: "AWS_SECRET_ACCESS_KEY", Value: "aws-secret"}}},
+	}
+
+	dst := newTestConfig()
+	current := dst.DeepCopy()
+	Merge(dst, src, MergeOverwrite)
+
+	diff, err := Diff(current, dst, "before", "after")
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "--- before\n+++ after\n")
+	assert.Contains(t, diff, "-    server: https://c1\n+    server: https://other\n")
+	assert.Contains(t, diff, "-    token: REDACTED")
+	assert.Contains(t, diff, "+    token: REDACTED")
+	assert.NotContains(t, diff, "t1")
+	assert.NotContains(t, diff, "t9")
+	assert.Contains(t, diff, "+        id-token: REDACTED")
+	assert.Contains(t, diff, "+      - name: AWS_SECRET_ACCESS_KEY")
+	for _, secret := range []string{"oidc-client-secret", "oidc-id-token", "oidc-refresh-token", "aws-secret"} {
+		assert.NotContains(t, diff, secret)
+	}
+	// the source is not modified
+	assert.Equal(t, "oidc-id-token", dst.AuthInfos["oidc"].AuthProvider.Config["id-token"])
+
+	diff, err = Diff(current, current, "before", "after")
+	assert.NoError(t, err)
+	assert.Equal(t, "", diff)
+}
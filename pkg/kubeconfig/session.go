@@ -0,0 +1,244 @@
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/mitchellh/go-homedir"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// SessionEnv defines the environment variable that holds the session kubeconfig
+	SessionEnv = "KW_SESSION"
+
+	defaultSessionDir = "~/.kube/kw-sessions"
+	sessionPrefix     = "session-"
+)
+
+// Session represents a kubeconfig overlay that is valid only for the shell
+// that exported it. The session file only holds the current context and
+// its namespace, it is loaded before the kubeconfig files, so its values
+// take precedence and the credentials are still read from the files.
+type Session struct {
+	Path string
+	// Files are the kubeconfig files loaded after the session file
+	Files []string
+}
+
+// NewSession creates a new session file layered on top of the kubeconfig
+// files. The owner is the process whose shell uses the session, the file
+// is removed by PruneSessions once that process has exited.
+func NewSession(files []string, owner int) (*Session, error) {
+	dir, err := sessionDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("error creating the session directory: %w", err)
+	}
+
+	// a failure to prune does not prevent the new session from working
+	_ = PruneSessions()
+
+	f, err := ioutil.TempFile(dir, fmt.Sprintf("%s%d-*.yml", sessionPrefix, owner))
+	if err != nil {
+		return nil, fmt.Errorf("error creating the session file: %w", err)
+	}
+	defer f.Close()
+
+	return &Session{Path: f.Name(), Files: files}, nil
+}
+
+// CurrentSession returns the session exported in the environment, otherwise nil
+func CurrentSession() *Session {
+	p := os.Getenv(SessionEnv)
+	if p == "" {
+		return nil
+	}
+
+	s := &Session{Path: p}
+	for _, f := range filepath.SplitList(os.Getenv(clientcmd.RecommendedConfigPathEnvVar)) {
+		if !s.Owns(f) {
+			s.Files = append(s.Files, f)
+		}
+	}
+
+	return s
+}
+
+// Owns reports whether the path refers to the session file
+func (s *Session) Owns(path string) bool {
+	return filepath.Clean(path) == filepath.Clean(s.Path)
+}
+
+// Apply writes the given context, as the current context, to the session
+// file. Only the context itself is written, its cluster and user are
+// still read from the kubeconfig files.
+func (s *Session) Apply(c *clientcmdapi.Config, ctx string) error {
+	sc := clientcmdapi.NewConfig()
+	sc.CurrentContext = ctx
+
+	if ctx != "" {
+		context, ok := c.Contexts[ctx]
+		if !ok {
+			return fmt.Errorf("context not found: %s", ctx)
+		}
+
+		sc.Contexts[ctx] = context.DeepCopy()
+		sc.Contexts[ctx].LocationOfOrigin = ""
+	}
+
+	if err := clientcmd.WriteToFile(*sc, s.Path); err != nil {
+		return fmt.Errorf("error writing the session file: %w", err)
+	}
+
+	return nil
+}
+
+// ConfigAccess returns the access to the kubeconfig files under the
+// session file, it is used to modify the files shared with other shells
+func (s *Session) ConfigAccess(rules *clientcmd.ClientConfigLoadingRules) clientcmd.ConfigAccess {
+	return &filesAccess{files: s.Files, rules: rules}
+}
+
+// Env returns the environment variables that enable the session. The
+// session file is loaded before the kubeconfig files, the first file
+// defining a value wins, so its current context and namespace are used.
+func (s *Session) Env() []string {
+	files := append([]string{s.Path}, s.Files...)
+
+	return []string{
+		fmt.Sprintf("%s=%s", clientcmd.RecommendedConfigPathEnvVar, strings.Join(files, string(filepath.ListSeparator))),
+		fmt.Sprintf("%s=%s", SessionEnv, s.Path),
+	}
+}
+
+// Remove deletes the session file
+func (s *Session) Remove() error {
+	err := os.Remove(s.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing the session file: %w", err)
+	}
+
+	return nil
+}
+
+// PruneSessions removes the session files whose owner process has exited
+func PruneSessions() error {
+	dir, err := sessionDir()
+	if err != nil {
+		return err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error reading the session directory: %w", err)
+	}
+
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name(), sessionPrefix) {
+			continue
+		}
+
+		if owner, ok := sessionOwner(f.Name()); ok && processExists(owner) {
+			continue
+		}
+
+		s := &Session{Path: filepath.Join(dir, f.Name())}
+		if err := s.Remove(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sessionDir() (string, error) {
+	dir, err := homedir.Expand(defaultSessionDir)
+	if err != nil {
+		return "", fmt.Errorf("error getting the session directory: %w", err)
+	}
+	return dir, nil
+}
+
+// sessionOwner returns the process id encoded in the name of a session
+// file, i.e. session-<pid>-<random>.yml
+func sessionOwner(name string) (int, bool) {
+	parts := strings.Split(strings.TrimPrefix(name, sessionPrefix), "-")
+	if len(parts) != 2 {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+
+	return pid, true
+}
+
+func processExists(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// filesAccess loads and modifies a fixed list of kubeconfig files
+type filesAccess struct {
+	files []string
+	rules *clientcmd.ClientConfigLoadingRules
+}
+
+func (a *filesAccess) GetLoadingPrecedence() []string {
+	return a.files
+}
+
+func (a *filesAccess) GetStartingConfig() (*clientcmdapi.Config, error) {
+	rules := *a.rules
+	rules.ExplicitPath = ""
+	rules.Precedence = a.files
+
+	c, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(&rules, &clientcmd.ConfigOverrides{}).RawConfig()
+	if os.IsNotExist(err) {
+		return clientcmdapi.NewConfig(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// GetDefaultFilename returns the first existing file, otherwise the last one
+func (a *filesAccess) GetDefaultFilename() string {
+	for _, f := range a.files {
+		if _, err := os.Stat(f); err == nil {
+			return f
+		}
+	}
+	return a.files[len(a.files)-1]
+}
+
+func (a *filesAccess) IsExplicitFile() bool {
+	return false
+}
+
+func (a *filesAccess) GetExplicitFile() string {
+	return ""
+}
@@ -0,0 +1,90 @@
+package kubeconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestSessionApply(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s := &Session{Path: filepath.Join(dir, "session.yml")}
+	assert.NoError(t, s.Apply(newTestConfig(), "b"))
+
+	c, err := clientcmd.LoadFromFile(s.Path)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", c.CurrentContext)
+	assert.Len(t, c.Contexts, 1)
+	assert.Empty(t, c.Clusters)
+	assert.Empty(t, c.AuthInfos)
+
+	assert.Error(t, s.Apply(newTestConfig(), "x"))
+}
+
+func TestSessionEnv(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "kw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config")
+	c := newTestConfig()
+	c.Contexts["b"].Namespace = "kube-system"
+	assert.NoError(t, clientcmd.WriteToFile(*c, file))
+
+	s := &Session{Path: filepath.Join(dir, "session.yml"), Files: []string{file}}
+	sc := newTestConfig()
+	sc.Contexts["b"].Namespace = "payments"
+	assert.NoError(t, s.Apply(sc, "b"))
+
+	env := s.Env()
+	assert.Equal(t, []string{"KUBECONFIG=" + s.Path + ":" + file, "KW_SESSION=" + s.Path}, env)
+
+	// the session file wins over the kubeconfig files
+	const envVar = "KW_TEST_KUBECONFIG"
+	os.Setenv(envVar, strings.TrimPrefix(env[0], "KUBECONFIG="))
+	defer os.Unsetenv(envVar)
+
+	po := clientcmd.NewDefaultPathOptions()
+	po.EnvVar = envVar
+
+	loaded, err := po.GetStartingConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "b", loaded.CurrentContext)
+	assert.Equal(t, "payments", loaded.Contexts["b"].Namespace)
+	assert.Equal(t, "u2", loaded.Contexts["b"].AuthInfo)
+	assert.Equal(t, "t2", loaded.AuthInfos["u2"].Token)
+}
+
+func TestSessionOwner(t *testing.T) {
+
+	tests := []struct {
+		TestName string
+		Name     string
+		Owner    int
+		Ok       bool
+	}{
+		{"owner", "session-1234-987654.yml", 1234, true},
+		{"without owner", "session-987654.yml", 0, false},
+		{"invalid owner", "session-abc-987654.yml", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.TestName, func(t *testing.T) {
+			owner, ok := sessionOwner(tt.Name)
+			assert.Equal(t, tt.Owner, owner)
+			assert.Equal(t, tt.Ok, ok)
+		})
+	}
+
+	assert.True(t, processExists(os.Getpid()))
+}
@@ -4,19 +4,14 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"os"
+	"time"
 
-	"github.com/mitchellh/go-homedir"
 	core "k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8s "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-const (
-	defaultPath = "~/.kube/config"
-)
-
 // Kubernetes provides the API operation methods for making requests to Kubernetes
 type Kubernetes struct {
 	cli *k8s.Clientset
@@ -30,22 +25,26 @@ type LogStream struct {
 	Message   string
 }
 
-// NewKubernetes creates a new Clientset for the given config
-func NewKubernetes() (*Kubernetes, error) {
+// NewKubernetes creates a new Clientset for the current context
+func NewKubernetes(rules *clientcmd.ClientConfigLoadingRules) (*Kubernetes, error) {
+	return NewKubernetesForContext(rules, "")
+}
 
-	c := os.Getenv("K8S_CONFIG")
-	if c == "" {
-		var err error
-		c, err = homedir.Expand(defaultPath)
-		if err != nil {
-			return nil, fmt.Errorf("error getting the kubernetes default path: %w", err)
-		}
-	}
+// NewKubernetesForContext creates a new Clientset for the given context,
+// the current context is used when it is empty
+func NewKubernetesForContext(rules *clientcmd.ClientConfigLoadingRules, context string) (*Kubernetes, error) {
+	return NewKubernetesWithTimeout(rules, context, 0)
+}
 
-	config, err := clientcmd.BuildConfigFromFlags("", c)
+// NewKubernetesWithTimeout creates a new Clientset for the given context
+// whose requests fail after the timeout, zero means no timeout
+func NewKubernetesWithTimeout(rules *clientcmd.ClientConfigLoadingRules, context string, timeout time.Duration) (*Kubernetes, error) {
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("error building config from a kubeconfig filepath: %w", err)
 	}
+	config.Timeout = timeout
 
 	cli, err := k8s.NewForConfig(config)
 	if err != nil {
@@ -77,6 +76,16 @@ func (k *Kubernetes) Pods(ns string) ([]core.Pod, error) {
 	return pods.Items, nil
 }
 
+// ResourceQuotas lists all resource quotas for a given namespace
+func (k *Kubernetes) ResourceQuotas(ns string) ([]core.ResourceQuota, error) {
+	quotas, err := k.cli.CoreV1().ResourceQuotas(ns).List(meta.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing the resource quotas: %w", err)
+	}
+
+	return quotas.Items, nil
+}
+
 // Pod gets a pod resource for a given namespace and pod
 func (k *Kubernetes) Pod(ns, p string) (*core.Pod, error) {
 	opts := meta.GetOptions{}
@@ -115,7 +124,8 @@ func (k *Kubernetes) Logs(ns, p, c string, stream chan *LogStream) error {
 		return err
 	}
 
-	ctx, _ := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	for _, pod := range pods {
 		// use the container name specified or list all containers in pod
@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Authentication results of a probe
+const (
+	AuthOK           = "ok"
+	AuthUnauthorized = "unauthorized"
+	AuthForbidden    = "forbidden"
+	AuthUnknown      = "unknown"
+)
+
+// Probe contains the result of a cluster reachability check
+type Probe struct {
+	Context   string
+	Reachable bool
+	Version   string
+	Latency   time.Duration
+	Auth      string
+	Err       error
+}
+
+// ProbeContexts checks the API servers of the given contexts, at most
+// parallel contexts are checked at the same time
+func ProbeContexts(c *clientcmdapi.Config, contexts []string, timeout time.Duration, parallel int) []*Probe {
+	probes := make([]*Probe, len(contexts))
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, parallel)
+	)
+	for i, ctx := range contexts {
+		wg.Add(1)
+		go func(i int, ctx string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			probes[i] = ProbeContext(c, ctx, timeout)
+		}(i, ctx)
+	}
+	wg.Wait()
+
+	return probes
+}
+
+// ProbeContext requests the version of the API server of a context and
+// checks whether its credentials are accepted
+func ProbeContext(c *clientcmdapi.Config, context string, timeout time.Duration) *Probe {
+	p := &Probe{Context: context, Auth: AuthUnknown}
+
+	rc, err := clientcmd.NewNonInteractiveClientConfig(*c, context, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		p.Err = err
+		return p
+	}
+	rc.Timeout = timeout
+
+	dc, err := discovery.NewDiscoveryClientForConfig(rc)
+	if err != nil {
+		p.Err = err
+		return p
+	}
+
+	start := time.Now()
+	v, err := dc.ServerVersion()
+	p.Latency = time.Since(start)
+	if err != nil && !isAuthError(err) {
+		p.Err = err
+		return p
+	}
+
+	p.Reachable = true
+	if v != nil {
+		p.Version = v.GitVersion
+	}
+
+	err = dc.RESTClient().Get().AbsPath("/api").Do().Error()
+	switch {
+	case err == nil:
+		p.Auth = AuthOK
+	case apierrors.IsUnauthorized(err):
+		p.Auth = AuthUnauthorized
+	case apierrors.IsForbidden(err):
+		p.Auth = AuthForbidden
+	default:
+		p.Err = err
+	}
+
+	return p
+}
+
+func isAuthError(err error) bool {
+	return apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err)
+}
@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestProbeContextsParallel(t *testing.T) {
+
+	var (
+		mu              sync.Mutex
+		running, maxRun int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		running++
+		if running > maxRun {
+			maxRun = running
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"gitVersion": "v1.17.4"}`)
+	}))
+	defer srv.Close()
+
+	c := clientcmdapi.NewConfig()
+	c.Clusters["up"] = &clientcmdapi.Cluster{Server: srv.URL}
+	c.Clusters["down"] = &clientcmdapi.Cluster{Server: "http://127.0.0.1:1"}
+	c.AuthInfos["u"] = &clientcmdapi.AuthInfo{}
+
+	var names []string
+	for i := 0; i < 6; i++ {
+		name := fmt.Sprintf("ctx-%d", i)
+		c.Contexts[name] = &clientcmdapi.Context{Cluster: "up", AuthInfo: "u"}
+		names = append(names, name)
+	}
+	c.Contexts["down"] = &clientcmdapi.Context{Cluster: "down", AuthInfo: "u"}
+	names = append(names, "down")
+
+	probes := ProbeContexts(c, names, time.Second, 2)
+
+	assert.LessOrEqual(t, maxRun, 2)
+	assert.True(t, probes[0].Reachable)
+	assert.Equal(t, "v1.17.4", probes[0].Version)
+	assert.False(t, probes[6].Reachable)
+	assert.Error(t, probes[6].Err)
+}